@@ -0,0 +1,105 @@
+package wtester
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxCallerDepth is the maximum number of frames captured per Write
+// when caller attribution is enabled.
+const maxCallerDepth = 32
+
+// defaultCallerSkips are the function name prefixes of the frames
+// that never count as the caller of a record: the logging libraries
+// from the standard library and the plumbing between them and the
+// WTester.
+var defaultCallerSkips = []string{
+	"log.",
+	"log/slog.",
+	"fmt.",
+	"io.",
+	"bufio.",
+	"sync.",
+	"runtime.",
+}
+
+// pkgPath is the import path of this package. Frames from its non test
+// files are internal plumbing and are always skipped.
+var pkgPath = reflect.TypeOf(WTester{}).PkgPath()
+
+// WithCallers enables caller attribution. When enabled, Write captures
+// the stack of the goroutine writing the record and every [ErrorRecord]
+// produced for it carries the file:line of the first application frame.
+//
+// Frames from the log and log/slog packages are skipped, as well as
+// frames whose function name starts with any of the provided prefixes.
+// Use the prefixes to skip logging libraries or wrappers of your own,
+// e.g. "go.uber.org/zap." or "github.com/acme/logging.".
+//
+// Capturing the stack is an overhead paid on every Write, so it is
+// disabled by default.
+func (l *WTester) WithCallers(skipPrefixes ...string) *WTester {
+	l.callers = true
+	l.callerSkips = append(l.callerSkips, skipPrefixes...)
+	return l
+}
+
+// captureCallers returns the program counters of the current stack,
+// skipping the frames of captureCallers and its caller.
+func (l *WTester) captureCallers() []uintptr {
+	if !l.callers {
+		return nil
+	}
+
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// callerOf returns the file:line of the first application frame in pcs
+// or an empty string if there is none.
+func (l *WTester) callerOf(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !l.skipFrame(frame) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// skipFrame reports whether the frame belongs to a logging library or
+// to this package.
+func (l *WTester) skipFrame(frame runtime.Frame) bool {
+	if frame.Function == "" {
+		return true
+	}
+
+	if strings.HasPrefix(frame.Function, pkgPath+".") && !strings.HasSuffix(frame.File, "_test.go") {
+		return true
+	}
+
+	for _, prefix := range defaultCallerSkips {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	for _, prefix := range l.callerSkips {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
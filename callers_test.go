@@ -0,0 +1,89 @@
+package wtester
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWTester_WithCallersRecordsApplicationFrame(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).WithCallers()
+	wt.Expect("no errors", Not(StringMatch("error", false))).Every()
+
+	logger := log.New(wt, "", log.LstdFlags)
+	_, file, line, _ := runtime.Caller(0)
+	logger.Printf("error in server")
+
+	assertCaller(t, wt, fmt.Sprintf("%s:%d", file, line+1))
+}
+
+func TestWTester_WithCallersSkipsSlogFrames(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).WithCallers()
+	wt.Expect("no errors", Not(StringMatch("error", false))).Every()
+
+	logger := slog.New(slog.NewJSONHandler(wt, nil))
+	_, file, line, _ := runtime.Caller(0)
+	logger.Info("error parsing document")
+
+	assertCaller(t, wt, fmt.Sprintf("%s:%d", file, line+1))
+}
+
+func TestWTester_WithCallersSkipsConfiguredPrefixes(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).WithCallers(pkgPath + ".logWrapper")
+	wt.Expect("no errors", Not(StringMatch("error", false))).Every()
+
+	_, file, line, _ := runtime.Caller(0)
+	logWrapper(wt, "error in server")
+
+	assertCaller(t, wt, fmt.Sprintf("%s:%d", file, line+1))
+}
+
+func TestWTester_CallersDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("no errors", Not(StringMatch("error", false))).Every()
+
+	wt.Write([]byte("error in server"))
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if c := ve.Errs[0].Errors[0].Caller; c != "" {
+		t.Fatalf("expected no caller, got %q", c)
+	}
+}
+
+func logWrapper(w io.Writer, msg string) {
+	log.New(w, "", 0).Print(msg)
+}
+
+func assertCaller(t *testing.T, wt *WTester, expected string) {
+	t.Helper()
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	rec := ve.Errs[0].Errors[0]
+	if rec.Caller != expected {
+		t.Fatalf("expected caller %q, got %q", expected, rec.Caller)
+	}
+
+	if !strings.Contains(ve.Error(), "at "+expected) {
+		t.Fatalf("expected report to contain the caller, got %q", ve.Error())
+	}
+}
@@ -0,0 +1,103 @@
+package wtester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay is how long Run waits for the output of a command
+// once it exited or was killed, e.g. when the processes it started
+// keep its output open.
+const commandWaitDelay = time.Second
+
+// Cmd runs an external command and feeds its standard output and
+// standard error to a [WTester], on the [StreamStdout] and
+// [StreamStderr] streams respectively, one record per line.
+type Cmd struct {
+	// Cmd is the underlying command. It can be configured,
+	// e.g. its Env or Dir, before calling Run. Its Stdout
+	// and Stderr are owned by the Cmd and must not be set.
+	Cmd *exec.Cmd
+
+	ctx context.Context
+	wt  *WTester
+}
+
+// Command returns a Cmd to run the named program with the given
+// arguments. The process is killed if the context is done before
+// it exits on its own. Its output is read for up to a second after it
+// exited or was killed, the processes it started that still hold its
+// output are not waited for.
+func Command(ctx context.Context, name string, args ...string) *Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = commandWaitDelay
+
+	return &Cmd{
+		Cmd: cmd,
+		ctx: ctx,
+		wt:  NewWTester(io.Discard),
+	}
+}
+
+// Tester returns the WTester fed with the output of the command.
+// Set the expectations on it before calling Run and validate them
+// after Run returns.
+func (c *Cmd) Tester() *WTester {
+	return c.wt
+}
+
+// Run starts the command and waits for it to exit and for its output
+// to be fully written to the WTester.
+//
+// Exiting with a non-zero code is not an error, check [Cmd.ExitCode]
+// instead, nor is exiting while processes it started still hold its
+// output. An error is returned if the command could not be started,
+// if its output could not be read or if the command was killed because
+// the context was done, in which case the error is the context's error.
+func (c *Cmd) Run() error {
+	stdout := &lineWriter{w: c.wt.Stream(StreamStdout)}
+	stderr := &lineWriter{w: c.wt.Stream(StreamStderr)}
+
+	c.Cmd.Stdout = stdout
+	c.Cmd.Stderr = stderr
+
+	err := c.Cmd.Run()
+
+	// The process has exited, flush any unterminated line.
+	if ferr := stdout.Flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+	if ferr := stderr.Flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+
+	// The command exited on its own, possibly leaving processes that
+	// still held its output when the wait delay expired.
+	var exitErr *exec.ExitError
+	exited := errors.As(err, &exitErr) && exitErr.Exited() ||
+		errors.Is(err, exec.ErrWaitDelay) && c.Cmd.ProcessState.Exited()
+
+	// The context being done only matters if it stopped the command.
+	if ctxErr := c.ctx.Err(); ctxErr != nil && err != nil && !exited {
+		return ctxErr
+	}
+
+	if exited {
+		return nil
+	}
+
+	return err
+}
+
+// ExitCode returns the exit code of the exited command, or -1 if the
+// command has not exited or was terminated by a signal.
+func (c *Cmd) ExitCode() int {
+	if c.Cmd.ProcessState == nil {
+		return -1
+	}
+
+	return c.Cmd.ProcessState.ExitCode()
+}
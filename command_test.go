@@ -0,0 +1,185 @@
+package wtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is not a real test. It is the process
+// run by the Command tests.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("WTESTER_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+
+	switch args[1] {
+	case "logs":
+		fmt.Fprintln(os.Stdout, `{"level":"INFO","msg":"started"}`)
+		fmt.Fprintln(os.Stdout, `{"level":"INFO","msg":"stopped"}`)
+		fmt.Fprint(os.Stderr, "warning: no config file")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+	case "sleep-for":
+		d, _ := time.ParseDuration(args[2])
+		time.Sleep(d)
+	case "orphan":
+		// Leave a child holding the output.
+		child := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep-for", "5s")
+		child.Env = os.Environ()
+		child.Stdout = os.Stdout
+		child.Start()
+
+		time.Sleep(time.Minute)
+	case "detach":
+		// Exit leaving a child holding the output.
+		child := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep-for", "5s")
+		child.Env = os.Environ()
+		child.Stdout = os.Stdout
+		child.Start()
+
+		fmt.Fprintln(os.Stdout, "detached")
+	}
+
+	os.Exit(0)
+}
+
+func helperCommand(ctx context.Context, name string, args ...string) *Cmd {
+	cmd := Command(ctx, os.Args[0], append([]string{"-test.run=TestHelperProcess", "--", name}, args...)...)
+	cmd.Cmd.Env = append(os.Environ(), "WTESTER_HELPER_PROCESS=1")
+	return cmd
+}
+
+func TestCommand_CapturesStreamsAndExitCode(t *testing.T) {
+	t.Parallel()
+
+	cmd := helperCommand(context.Background(), "logs")
+
+	wt := cmd.Tester()
+	wt.Expect("stdout is JSON lines", RegexMatch(`^\{.*\}\n$`)).Every().OnStreams(StreamStdout)
+	wt.Expect("two stdout lines", PrefixMatch("{")).WithMin(2).WithMax(2)
+	wt.Expect("no stderr warnings", Not(StringMatch("warning", false))).Every().OnStreams(StreamStderr)
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if code := cmd.ExitCode(); code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if len(ve.Errs) != 1 || ve.Errs[0].Title != "no stderr warnings" {
+		t.Fatalf("expected only the stderr expectation to fail, got %v", ve)
+	}
+
+	if !strings.Contains(ve.Error(), "[stderr] warning: no config file") {
+		t.Fatalf("expected report to contain the stderr line, got %q", ve.Error())
+	}
+}
+
+func TestCommand_KillsProcessWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	cmd := helperCommand(ctx, "sleep")
+
+	start := time.Now()
+	err := cmd.Run()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected process to be killed, ran for %s", elapsed)
+	}
+}
+
+func TestCommand_DoesNotWaitForOrphans(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	cmd := helperCommand(ctx, "orphan")
+
+	start := time.Now()
+	err := cmd.Run()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Fatalf("expected Run to return once the process was killed, took %s", elapsed)
+	}
+}
+
+func TestCommand_ExitedBeforeTheContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := helperCommand(ctx, "logs")
+
+	// Cancel the context once the process exited, while
+	// its output is still being checked.
+	cmd.Tester().ExpectFunc("exited", func([]byte) bool {
+		for !errors.Is(cmd.Cmd.Process.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		cancel()
+
+		return true
+	})
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if code := cmd.ExitCode(); code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+}
+
+func TestCommand_ExitsLeavingAChild(t *testing.T) {
+	t.Parallel()
+
+	cmd := helperCommand(context.Background(), "detach")
+	cmd.Tester().Expect("detached", StringMatch("detached\n", true)).WithMin(1).WithMax(1)
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Fatalf("expected Run to return once the process exited, took %s", elapsed)
+	}
+
+	if code := cmd.ExitCode(); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+
+	if err := cmd.Tester().Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
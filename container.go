@@ -0,0 +1,162 @@
+package wtester
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// criPattern matches a line of the CRI log format:
+// "<RFC 3339 time> <stream> <P|F> <message>".
+var criPattern = regexp.MustCompile(`^(\S+) (stdout|stderr) ([PF]) ?(.*)$`)
+
+// UnwrapContainerLogs makes the WTester strip the envelopes container
+// runtimes wrap the logs in, so that the expectations, JSON ones
+// included, check the payload written by the container:
+//
+//   - the Docker json-file format,
+//     {"log":"...\n","stream":"stdout","time":"..."};
+//   - the CRI format used by containerd and CRI-O,
+//     "<time> <stream> <P|F> <message>".
+//
+// Lines split by the runtime, Docker lines without a trailing newline
+// and CRI lines flagged P, are reassembled before being checked, and a
+// line still partial when validating is checked as is. The payloads
+// keep the stream they were written to, see [WTester.Stream], while
+// those written directly to the WTester are checked as records of the
+// runtime stream, "stdout" or "stderr", see [Expect.OnStreams]. Failing
+// records report the runtime stream and timestamp in
+// [ErrorRecord.RuntimeStream] and [ErrorRecord.Time]. Lines in neither
+// format are checked as they are. The underlying writer receives the
+// lines as written, envelopes included.
+//
+// Every line of a Write is unwrapped on its own, use [WTester.Scan] to
+// feed the WTester with logs read from a node.
+func (l *WTester) UnwrapContainerLogs() *WTester {
+	l.containers = &containerUnwrapper{
+		partials: make(map[[2]string]*rawRecord),
+	}
+
+	return l
+}
+
+// containerUnwrapper holds the partial lines being reassembled.
+type containerUnwrapper struct {
+	// partials are keyed by the stream written to and the runtime stream.
+	partials map[[2]string]*rawRecord
+	mu       sync.Mutex // guards partials
+}
+
+// dockerLine is a line of the Docker json-file format.
+type dockerLine struct {
+	Log    *string `json:"log"`
+	Stream string  `json:"stream"`
+	Time   string  `json:"time"`
+}
+
+// unwrap returns the complete payloads held by the lines of rec.
+func (u *containerUnwrapper) unwrap(rec *rawRecord) []*rawRecord {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var out []*rawRecord
+	for p := rec.p; len(p) > 0; {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+		}
+		p = p[len(line):]
+
+		payload, stream, ts, partial, ok := parseContainerLine(bytes.TrimRight(line, "\r\n"))
+		if !ok {
+			out = append(out, &rawRecord{
+				stream: rec.stream,
+				p:      line,
+				pcs:    rec.pcs,
+			})
+			continue
+		}
+
+		key := [2]string{rec.stream, stream}
+		inner := u.partials[key]
+		if inner == nil {
+			inner = &rawRecord{
+				stream:        rec.stream,
+				runtimeStream: stream,
+				time:          ts,
+				pcs:           rec.pcs,
+			}
+
+			if inner.stream == "" {
+				inner.stream = stream
+			}
+		}
+		inner.p = append(inner.p, payload...)
+
+		if partial {
+			u.partials[key] = inner
+			continue
+		}
+
+		delete(u.partials, key)
+		if !bytes.HasSuffix(inner.p, []byte("\n")) {
+			inner.p = append(inner.p, '\n')
+		}
+
+		out = append(out, inner)
+	}
+
+	return out
+}
+
+// flush returns the lines still partial, completed with a newline,
+// and forgets them.
+func (u *containerUnwrapper) flush() []*rawRecord {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	keys := slices.SortedFunc(maps.Keys(u.partials), func(a, b [2]string) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	out := make([]*rawRecord, 0, len(keys))
+	for _, key := range keys {
+		inner := u.partials[key]
+		inner.p = append(inner.p, '\n')
+		out = append(out, inner)
+	}
+	clear(u.partials)
+
+	return out
+}
+
+// parseContainerLine parses a line in the Docker json-file or CRI
+// format. ok is false if the line is in neither.
+func parseContainerLine(line []byte) (payload []byte, stream string, ts time.Time, partial, ok bool) {
+	if len(line) > 0 && line[0] == '{' {
+		var d dockerLine
+		if err := json.Unmarshal(line, &d); err != nil || d.Log == nil || d.Stream == "" {
+			return nil, "", time.Time{}, false, false
+		}
+
+		ts, _ = time.Parse(time.RFC3339Nano, d.Time)
+		return []byte(*d.Log), d.Stream, ts, !bytes.HasSuffix([]byte(*d.Log), []byte("\n")), true
+	}
+
+	m := criPattern.FindSubmatch(line)
+	if m == nil {
+		return nil, "", time.Time{}, false, false
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, string(m[1]))
+	if err != nil {
+		return nil, "", time.Time{}, false, false
+	}
+
+	return m[4], string(m[2]), ts, string(m[3]) == "P", true
+}
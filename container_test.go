@@ -0,0 +1,131 @@
+package wtester
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWTester_UnwrapContainerLogs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"Docker json-file": `{"log":"{\"level\":\"INFO\",\"msg\":\"started\"}\n","stream":"stdout","time":"2024-01-02T12:30:45.123456789Z"}
+{"log":"{\"level\":\"INFO\",","stream":"stdout","time":"2024-01-02T12:30:46Z"}
+{"log":"\"msg\":\"split by the runtime\"}\n","stream":"stdout","time":"2024-01-02T12:30:46Z"}
+{"log":"warning: no config file\n","stream":"stderr","time":"2024-01-02T12:30:47Z"}
+`,
+		"CRI": `2024-01-02T12:30:45.123456789Z stdout F {"level":"INFO","msg":"started"}
+2024-01-02T12:30:46Z stdout P {"level":"INFO",
+2024-01-02T12:30:46Z stdout F "msg":"split by the runtime"}
+2024-01-02T12:30:47Z stderr F warning: no config file
+`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard).UnwrapContainerLogs()
+			wt.Expect("stdout is JSON", DecodeMatch(JSONDecoder, func(map[string]any) bool { return true })).Every().WithMin(2).WithMax(2).OnStreams(StreamStdout)
+			wt.Expect("reassembled", StringMatch(`{"level":"INFO","msg":"split by the runtime"}`+"\n", true)).WithMin(1)
+			wt.Expect("no warnings", Not(StringMatch("warning", false))).Every().OnStreams(StreamStderr)
+
+			if err := wt.Scan(strings.NewReader(input)); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			ve, ok := wt.Validate().(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors")
+			}
+
+			if len(ve.Errs) != 1 || ve.Errs[0].Title != "no warnings" {
+				t.Fatalf("expected only the stderr expectation to fail, got %v", ve)
+			}
+
+			rec := ve.Errs[0].Errors[0]
+			if string(rec.Bytes) != "warning: no config file\n" || rec.Stream != StreamStderr {
+				t.Fatalf("expected the unwrapped stderr line, got [%s] %q", rec.Stream, rec.Bytes)
+			}
+
+			if expected := time.Date(2024, 1, 2, 12, 30, 47, 0, time.UTC); !rec.Time.Equal(expected) {
+				t.Fatalf("expected runtime time %v, got %v", expected, rec.Time)
+			}
+		})
+	}
+}
+
+func TestWTester_UnwrapContainerLogsPassesOtherLines(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).UnwrapContainerLogs()
+	wt.Expect("plain line", StringMatch("plain line\n", true)).WithMin(1).WithMax(1)
+
+	io.WriteString(wt, "plain line\n")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_UnwrapContainerLogsKeepsNamedStreams(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).UnwrapContainerLogs()
+	wt.Expect("pod-a has no warnings", Not(StringMatch("warning", false))).Every().WithMin(0).OnStreams("pod-a")
+
+	io.WriteString(wt.Stream("pod-a"), "2024-01-02T12:30:45Z stderr F warning: no config file\n")
+	io.WriteString(wt.Stream("pod-b"), "2024-01-02T12:30:45Z stderr F warning: ignored\n")
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok || len(ve.Errs[0].Errors) != 1 {
+		t.Fatalf("expected the pod-a warning only, got %v", ve)
+	}
+
+	er := ve.Errs[0].Errors[0]
+	if er.Stream != "pod-a" || er.RuntimeStream != StreamStderr {
+		t.Fatalf("expected pod-a stream and stderr runtime stream, got %q and %q", er.Stream, er.RuntimeStream)
+	}
+
+	if !strings.HasPrefix(er.Error(), "[pod-a stderr] warning: no config file") {
+		t.Fatalf("expected both streams reported, got %q", er.Error())
+	}
+}
+
+func TestWTester_UnwrapContainerLogsFlushesPartialLines(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).UnwrapContainerLogs()
+	wt.Expect("partial line", StringMatch("killed before the end\n", true)).WithMin(1).WithMax(1)
+
+	io.WriteString(wt, "2024-01-02T12:30:45Z stdout P killed before \n")
+	io.WriteString(wt, "2024-01-02T12:30:45Z stdout P the end\n")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_UnwrapContainerLogsGroupsEachRuntimeStream(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).UnwrapContainerLogs().GroupRecords(GroupByIndent())
+	wt.Expect("no panics", Not(StringMatch("panic", false))).Every().WithMin(0).OnStreams("pod-a")
+
+	w := wt.Stream("pod-a")
+	io.WriteString(w, "2024-01-02T12:30:45Z stdout F panic: boom\n")
+	io.WriteString(w, "2024-01-02T12:30:45Z stderr F  warning: slow\n")
+	io.WriteString(w, "2024-01-02T12:30:45Z stdout F \tat main.go:12\n")
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok || len(ve.Errs[0].Errors) != 1 {
+		t.Fatalf("expected a single panic, got %v", ve)
+	}
+
+	er := ve.Errs[0].Errors[0]
+	if string(er.Bytes) != "panic: boom\n\tat main.go:12\n" || er.RuntimeStream != StreamStdout {
+		t.Fatalf("expected the stdout stack trace, got [%s] %q", er.RuntimeStream, er.Bytes)
+	}
+}
@@ -0,0 +1,131 @@
+package wtester
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ControlScanner is an [Expecter] that matches the records free of
+// control characters. Create it with [NoControlChars].
+type ControlScanner struct {
+	allowANSI bool
+}
+
+// NoControlChars returns an Expecter that flags the characters user
+// input can use to forge log lines or attack the terminal of whoever
+// reads them:
+//
+//   - line breaks, CR or LF, inside a record, other than the single
+//     trailing newline framing it;
+//   - ANSI/VT escape sequences;
+//   - NUL bytes and the other C0 and C1 control characters, tabs
+//     excluded;
+//   - Unicode bidirectional embeddings, overrides and isolates.
+//
+// It matches the records where nothing is found, use it with
+// [Expect.Every]. It complements [ValidUTF8], invalid UTF-8 is
+// not reported.
+func NoControlChars() *ControlScanner {
+	return &ControlScanner{}
+}
+
+// AllowANSI allows ANSI escape sequences, for the loggers that
+// colorize their output on purpose.
+func (s *ControlScanner) AllowANSI() *ControlScanner {
+	s.allowANSI = true
+	return s
+}
+
+func (s *ControlScanner) Expect(actual []byte) bool {
+	return len(s.scan(actual)) == 0
+}
+
+// Explain returns an error describing every offending code point
+// and its byte offset in the record.
+func (s *ControlScanner) Explain(actual []byte) error {
+	return errors.Join(s.scan(actual)...)
+}
+
+func (s *ControlScanner) scan(actual []byte) []error {
+	// The trailing newline frames the record, it is not part of it.
+	p := bytes.TrimSuffix(actual, []byte("\n"))
+	p = bytes.TrimSuffix(p, []byte("\r"))
+
+	var errs []error
+	report := func(what string, r rune, offset int) {
+		errs = append(errs, fmt.Errorf("%s %U at offset %d", what, r, offset))
+	}
+
+	for i := 0; i < len(p); {
+		r, size := utf8.DecodeRune(p[i:])
+
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8, reported by ValidUTF8.
+		case r == '\n' || r == '\r':
+			report("line break", r, i)
+		case r == 0x1b:
+			seq, ok := ansiSequenceLen(p[i:])
+			if !s.allowANSI || !ok {
+				report("ANSI escape sequence", r, i)
+			}
+			size = seq
+		case r == 0:
+			report("NUL byte", r, i)
+		case r < 0x20 && r != '\t', r == 0x7f:
+			report("control character", r, i)
+		case r >= 0x80 && r <= 0x9f:
+			report("C1 control character", r, i)
+		case (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069):
+			report("bidirectional control", r, i)
+		}
+
+		i += size
+	}
+
+	return errs
+}
+
+// ansiSequenceLen returns the length of the escape sequence starting
+// with the ESC at p[0] and whether it is well-formed: a CSI sequence,
+// ESC [ parameter and intermediate bytes then a final byte, an OSC
+// sequence, ESC ] printable bytes then BEL or ST, or a two bytes
+// escape. A malformed or truncated sequence ends before the byte
+// breaking it, so that byte, e.g. a line break, is still scanned.
+func ansiSequenceLen(p []byte) (int, bool) {
+	if len(p) < 2 {
+		return len(p), false
+	}
+
+	switch c := p[1]; {
+	case c == '[':
+		for i := 2; i < len(p); i++ {
+			switch {
+			case p[i] >= 0x20 && p[i] <= 0x3f:
+			case p[i] >= 0x40 && p[i] <= 0x7e:
+				return i + 1, true
+			default:
+				return i, false
+			}
+		}
+	case c == ']':
+		for i := 2; i < len(p); i++ {
+			switch {
+			case p[i] == 0x07:
+				return i + 1, true
+			case p[i] == 0x1b && i+1 < len(p) && p[i+1] == '\\':
+				return i + 2, true
+			case p[i] < 0x20:
+				return i, false
+			}
+		}
+	case c >= 0x20 && c <= 0x7e:
+		return 2, true
+	default:
+		return 1, false
+	}
+
+	return len(p), false
+}
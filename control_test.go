@@ -0,0 +1,106 @@
+package wtester
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNoControlChars(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		scanner  *ControlScanner
+		input    string
+		expected string
+	}{
+		"Clean record with trailing newline": {
+			input: "2024/01/02 12:30:45 user logged in\ttook 2ms\n",
+		},
+		"Escaped newline in JSON": {
+			input: `{"msg":"line one\nline two"}` + "\n",
+		},
+		"Forged line": {
+			input:    "user admin\n2024/01/02 12:30:45 login succeeded\n",
+			expected: "line break U+000A at offset 10",
+		},
+		"Carriage return": {
+			input:    "user admin\rlogin succeeded",
+			expected: "line break U+000D at offset 10",
+		},
+		"ANSI escape": {
+			input:    "user \x1b[2J\x1b[31mroot\x1b[0m",
+			expected: "ANSI escape sequence U+001B at offset 5",
+		},
+		"Allowed ANSI escape": {
+			scanner: NoControlChars().AllowANSI(),
+			input:   "\x1b[32mINFO\x1b[0m started\n",
+		},
+		"Allowed OSC title": {
+			scanner: NoControlChars().AllowANSI(),
+			input:   "\x1b]0;build\x07done \x1b]0;x\x1b\\\n",
+		},
+		"Line break inside an allowed CSI": {
+			scanner:  NoControlChars().AllowANSI(),
+			input:    "user \x1b[\n2024/01/02 12:30:45 login succeeded",
+			expected: "ANSI escape sequence U+001B at offset 5\nline break U+000A at offset 7",
+		},
+		"Line break inside an allowed OSC": {
+			scanner:  NoControlChars().AllowANSI(),
+			input:    "x \x1b]0;\nforged\x07",
+			expected: "ANSI escape sequence U+001B at offset 2\nline break U+000A at offset 6",
+		},
+		"Truncated allowed escape": {
+			scanner:  NoControlChars().AllowANSI(),
+			input:    "done \x1b[31",
+			expected: "ANSI escape sequence U+001B at offset 5",
+		},
+		"NUL byte": {
+			input:    "user\x00admin",
+			expected: "NUL byte U+0000 at offset 4",
+		},
+		"Backspace": {
+			input:    "user\badmin",
+			expected: "control character U+0008 at offset 4",
+		},
+		"C1 control": {
+			input:    "user\u009badmin",
+			expected: "C1 control character U+009B at offset 4",
+		},
+		"Bidi override": {
+			input:    "file \u202etxt.exe",
+			expected: "bidirectional control U+202E at offset 5",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			scanner := tt.scanner
+			if scanner == nil {
+				scanner = NoControlChars()
+			}
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, scanner).Every()
+			wt.Write([]byte(tt.input))
+
+			err := wt.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			explanation := ve.Errs[0].Errors[0].Err.Error()
+			if !strings.HasPrefix(explanation, tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, explanation)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package wtester
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// Titles the crashes found by [WTester.DetectCrashes] are reported under.
+const (
+	PanicTitle      = "go panic"
+	FatalErrorTitle = "go fatal error"
+	DataRaceTitle   = "go data race"
+)
+
+// raceDelimiter surrounds the reports of the race detector.
+const raceDelimiter = "=================="
+
+// DetectCrashes makes the WTester look for the crashes of Go programs
+// in everything written to it: panics, "fatal error:" runtime crashes
+// and the reports of the race detector. Each crash is reported as a
+// single failure under [PanicTitle], [FatalErrorTitle] or [DataRaceTitle],
+// holding the whole trace, even when it spans many writes.
+//
+// Crashes go to stderr and are easily missed by the expectations on
+// the logs. Use it with the output of a [Command] or with [CaptureStderr].
+// Traces still open when Validate is called are reported as they are.
+func (l *WTester) DetectCrashes() *WTester {
+	l.crashes = &crashDetector{
+		report:  l.appendError,
+		streams: make(map[string]*crashStream),
+	}
+
+	return l
+}
+
+// crashDetector groups the lines of the crash traces, per stream.
+type crashDetector struct {
+	report  func(title string, e ErrorRecord)
+	streams map[string]*crashStream
+	mu      sync.Mutex // guards streams
+}
+
+type crashStream struct {
+	// partial is the last line written, until its newline is.
+	partial []byte
+	// prev is the last complete line outside of a trace.
+	prev string
+	// title and trace are the kind and lines of the open trace.
+	title string
+	trace []string
+}
+
+// feed processes the bytes written to the stream.
+func (d *crashDetector) feed(stream string, p []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.streams[stream]
+	if s == nil {
+		s = &crashStream{}
+		d.streams[stream] = s
+	}
+
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			return
+		}
+
+		line := strings.TrimSuffix(string(s.partial[:i]), "\r")
+		s.partial = s.partial[i+1:]
+		d.line(stream, s, line)
+	}
+}
+
+// line processes a complete line of the stream.
+func (d *crashDetector) line(stream string, s *crashStream, line string) {
+	switch s.title {
+	case "":
+	case DataRaceTitle:
+		s.trace = append(s.trace, line)
+		if line == raceDelimiter {
+			d.emit(stream, s)
+		}
+		return
+	default:
+		if isTraceLine(line) {
+			s.trace = append(s.trace, line)
+			return
+		}
+
+		// The trace is over, the line may start another one.
+		d.emit(stream, s)
+	}
+
+	switch {
+	case line == "WARNING: DATA RACE":
+		s.title = DataRaceTitle
+		if s.prev == raceDelimiter {
+			s.trace = append(s.trace, s.prev)
+		}
+	case strings.HasPrefix(line, "panic: "):
+		s.title = PanicTitle
+	case strings.HasPrefix(line, "fatal error: "):
+		s.title = FatalErrorTitle
+	default:
+		s.prev = line
+		return
+	}
+
+	s.trace = append(s.trace, line)
+}
+
+// emit reports the open trace of the stream and closes it.
+func (d *crashDetector) emit(stream string, s *crashStream) {
+	// Blank lines separating the trace from what follows.
+	trace := s.trace
+	for len(trace) > 0 && trace[len(trace)-1] == "" {
+		trace = trace[:len(trace)-1]
+	}
+
+	d.report(s.title, ErrorRecord{
+		Bytes:  []byte(strings.Join(trace, "\n")),
+		Stream: stream,
+	})
+
+	s.title = ""
+	s.trace = nil
+	s.prev = ""
+}
+
+// flush reports the traces still open, with their unterminated
+// last line, if any.
+func (d *crashDetector) flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for stream, s := range d.streams {
+		if len(s.partial) > 0 {
+			line := string(s.partial)
+			s.partial = nil
+			d.line(stream, s, line)
+		}
+
+		if s.title != "" {
+			d.emit(stream, s)
+		}
+	}
+}
+
+// isTraceLine reports whether the line can be part of the goroutine
+// traces following a panic or a fatal error.
+func isTraceLine(line string) bool {
+	if line == "" || strings.HasPrefix(line, "\t") {
+		return true
+	}
+
+	for _, prefix := range []string{
+		"goroutine ",
+		"created by ",
+		"panic: ",
+		"fatal error: ",
+		"runtime stack:",
+		"[signal ",
+		"PC=",
+		"exit status ",
+		"...additional frames elided...",
+	} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	// Function frames, e.g. "main.main()" or "main.f(0x1, ...)".
+	i := strings.Index(line, "(")
+	return i > 0 && strings.HasSuffix(line, ")") && !strings.Contains(line[:i], " ")
+}
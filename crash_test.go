@@ -0,0 +1,108 @@
+package wtester
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const panicTrace = `panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+main.parse({0xc000012345, 0x3, 0x3})
+	/app/main.go:12 +0x1d
+main.main()
+	/app/main.go:7 +0x25
+exit status 2`
+
+const fatalTrace = `fatal error: concurrent map writes
+
+goroutine 7 [running]:
+main.worker(0xc000010000)
+	/app/main.go:21 +0x45
+created by main.main in goroutine 1
+	/app/main.go:15 +0x3c`
+
+const raceReport = `==================
+WARNING: DATA RACE
+Write at 0x00c00001c0a8 by goroutine 7:
+  main.main.func1()
+      /app/main.go:11 +0x3c
+
+Previous write at 0x00c00001c0a8 by main goroutine:
+  main.main()
+      /app/main.go:14 +0x9c
+==================`
+
+func TestWTester_DetectCrashes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		output string
+		title  string
+		trace  string
+	}{
+		"Panic": {
+			output: "starting\n" + panicTrace + "\n",
+			title:  PanicTitle,
+			trace:  panicTrace,
+		},
+		"Panic followed by logs": {
+			output: "starting\n" + panicTrace + "\nFAIL\tapp\t0.01s\n",
+			title:  PanicTitle,
+			trace:  panicTrace,
+		},
+		"Fatal error without trailing newline": {
+			output: fatalTrace,
+			title:  FatalErrorTitle,
+			trace:  fatalTrace,
+		},
+		"Data race": {
+			output: "starting\n" + raceReport + "\nstopped\n",
+			title:  DataRaceTitle,
+			trace:  raceReport,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard).DetectCrashes()
+
+			// One write per line, as framed by Command or Scan.
+			if err := wt.Scan(strings.NewReader(tt.output)); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			ve, ok := wt.Validate().(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors")
+			}
+
+			if len(ve.Errs) != 1 || len(ve.Errs[0].Errors) != 1 {
+				t.Fatalf("expected a single failure, got %v", ve)
+			}
+
+			if ve.Errs[0].Title != tt.title {
+				t.Fatalf("expected title %q, got %q", tt.title, ve.Errs[0].Title)
+			}
+
+			if trace := string(ve.Errs[0].Errors[0].Bytes); trace != tt.trace {
+				t.Fatalf("expected trace\n%s\ngot\n%s", tt.trace, trace)
+			}
+		})
+	}
+}
+
+func TestWTester_DetectCrashesIgnoresRegularLogs(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).DetectCrashes()
+	io.WriteString(wt, "level=ERROR msg=\"request failed\" err=\"panic: recovered in handler\"\n")
+	io.WriteString(wt, "==================\nlevel=INFO msg=done\n")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package wtester
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Decoding is the untyped view of a [Decoder], the one returned by
+// [DecodedExpecter.Decoder]. It is only implemented by [Decoder].
+type Decoding interface {
+	// Name identifies the representation the decoder produces.
+	Name() string
+
+	decodeAny(p []byte) (any, error)
+	expectAny(exp Expecter, v any) (ok, handled bool)
+	explainAny(exp Expecter, v any) (err error, handled bool)
+}
+
+// Decoder parses records into a representation of type T, shared by
+// every expectation that needs it. Each record is decoded at most once
+// per decoder name, however many expectations use it, and a record that
+// fails to decode is reported once, under the "<name> decoder" title,
+// instead of once per expectation.
+type Decoder[T any] struct {
+	name   string
+	decode func(p []byte) (T, error)
+}
+
+// NewDecoder returns a Decoder named name that parses records with
+// decode. Decoders producing different representations must have
+// different names.
+func NewDecoder[T any](name string, decode func(p []byte) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		name:   name,
+		decode: decode,
+	}
+}
+
+func (d *Decoder[T]) Name() string {
+	return d.name
+}
+
+// Decode parses a record.
+func (d *Decoder[T]) Decode(p []byte) (T, error) {
+	return d.decode(p)
+}
+
+func (d *Decoder[T]) decodeAny(p []byte) (any, error) {
+	return d.decode(p)
+}
+
+func (d *Decoder[T]) expectAny(exp Expecter, v any) (ok, handled bool) {
+	de, isDecoded := exp.(DecodedExpecter[T])
+	if !isDecoded {
+		return false, false
+	}
+
+	return de.ExpectDecoded(v.(T)), true
+}
+
+func (d *Decoder[T]) explainAny(exp Expecter, v any) (err error, handled bool) {
+	de, isDecoded := exp.(DecodedExplainer[T])
+	if !isDecoded {
+		return nil, false
+	}
+
+	return de.ExplainDecoded(v.(T)), true
+}
+
+// DecodedExpecter is implemented by expecters checking a decoded
+// representation of the records instead of their raw bytes. Decoder
+// returns the [Decoder] producing it, and ExpectDecoded is called
+// instead of Expect.
+type DecodedExpecter[T any] interface {
+	Expecter
+	Decoder() Decoding
+	ExpectDecoded(v T) bool
+}
+
+// DecodedExplainer is the [Explainer] counterpart of [DecodedExpecter].
+type DecodedExplainer[T any] interface {
+	ExplainDecoded(v T) error
+}
+
+// JSONDecoder unmarshals records into a map. It is the decoder used
+// by the [JSONExpecter] implementations.
+var JSONDecoder = NewDecoder("json", func(p []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(p, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %s", err.Error())
+	}
+
+	return m, nil
+})
+
+// DecodeFunc is a [DecodedExpecter] built from a decoder and a function.
+type DecodeFunc[T any] struct {
+	dec *Decoder[T]
+	f   func(v T) bool
+}
+
+// DecodeMatch returns an Expecter that checks the records
+// decoded by d with f.
+func DecodeMatch[T any](d *Decoder[T], f func(v T) bool) *DecodeFunc[T] {
+	return &DecodeFunc[T]{
+		dec: d,
+		f:   f,
+	}
+}
+
+// Only for satisfy the Expecter interface.
+func (f *DecodeFunc[T]) Expect(actual []byte) bool {
+	return false
+}
+
+func (f *DecodeFunc[T]) Decoder() Decoding {
+	return f.dec
+}
+
+func (f *DecodeFunc[T]) ExpectDecoded(v T) bool {
+	return f.f(v)
+}
+
+// RegisterDecoder makes the WTester use d for every expecter declaring
+// a decoder with the same name, e.g. to tune how a representation is
+// decoded without changing the expecters using it. d must produce the
+// same type as the decoders it replaces, the records checked by
+// expecters needing another type are reported as failures.
+func (l *WTester) RegisterDecoder(d Decoding) {
+	l.muDec.Lock()
+	defer l.muDec.Unlock()
+
+	l.decoders[d.Name()] = d
+}
+
+// decoderOf returns the decoder the expecter needs, if any. A decoder
+// registered under the same name must produce the same type.
+func (l *WTester) decoderOf(exp Expecter) (Decoding, error) {
+	var d Decoding
+	switch exp := exp.(type) {
+	case interface{ Decoder() Decoding }:
+		d = exp.Decoder()
+	case JSONExpecter:
+		d = JSONDecoder
+	default:
+		return nil, nil
+	}
+
+	l.muDec.RLock()
+	defer l.muDec.RUnlock()
+
+	registered, ok := l.decoders[d.Name()]
+	if !ok {
+		return d, nil
+	}
+
+	if reflect.TypeOf(registered) != reflect.TypeOf(d) {
+		return nil, fmt.Errorf("decoder %q registered as %T, the expectation needs %T", d.Name(), registered, d)
+	}
+
+	return registered, nil
+}
+
+// decoded is the cached result of a decoder for a record.
+type decoded struct {
+	v   any
+	err error
+}
+
+// decodeRecord decodes the record with d, at most once per decoder
+// name. A decode failure is reported the first time only.
+func (l *WTester) decodeRecord(rec *rawRecord, d Decoding) decoded {
+	if res, ok := rec.decoded[d.Name()]; ok {
+		return res
+	}
+
+	var res decoded
+	res.v, res.err = d.decodeAny(rec.p)
+	if rec.decoded == nil {
+		rec.decoded = make(map[string]decoded)
+	}
+	rec.decoded[d.Name()] = res
+
+	if res.err != nil {
+		l.appendError(d.Name()+" decoder", l.errorRecord(rec, res.err))
+	}
+
+	return res
+}
@@ -0,0 +1,166 @@
+package wtester
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWTester_DecoderDecodesOncePerRecord(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	csv := NewDecoder("csv", func(p []byte) ([]string, error) {
+		calls.Add(1)
+		if len(p) == 0 {
+			return nil, errors.New("empty record")
+		}
+
+		return strings.Split(string(p), ","), nil
+	})
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("three columns", DecodeMatch(csv, func(cols []string) bool { return len(cols) == 3 })).Every()
+	wt.Expect("level first", DecodeMatch(csv, func(cols []string) bool { return cols[0] == "INFO" })).Every()
+
+	wt.Write([]byte("INFO,started,42"))
+	wt.Write([]byte(""))
+
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 decodes, got %d", n)
+	}
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if len(ve.Errs) != 1 || ve.Errs[0].Title != "csv decoder" || len(ve.Errs[0].Errors) != 1 {
+		t.Fatalf("expected a single decode failure, got %v", ve)
+	}
+}
+
+func TestWTester_JSONDecodeFailureReportedOnce(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("password obfuscated", MaskPolicy().FullMask("password")).Every()
+	wt.Expect("token obfuscated", MaskPolicy().FullMask("token")).Every()
+
+	wt.Write([]byte("not json"))
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if n := strings.Count(ve.Error(), "failed to unmarshal JSON"); n != 1 {
+		t.Fatalf("expected a single decode failure, got %d: %v", n, ve)
+	}
+
+	for _, e := range ve.Errs {
+		if e.Title == "json decoder" && len(e.Errors) == 1 {
+			return
+		}
+	}
+
+	t.Fatalf("expected the failure under the decoder title, got %v", ve)
+}
+
+type levelExpecter struct{}
+
+func (levelExpecter) Expect([]byte) bool { return false }
+
+func (levelExpecter) Decoder() Decoding { return JSONDecoder }
+
+func (levelExpecter) ExpectDecoded(m map[string]any) bool {
+	return m["level"] == "INFO"
+}
+
+func (levelExpecter) ExplainDecoded(m map[string]any) error {
+	return errors.New("unexpected level " + m["level"].(string))
+}
+
+func TestWTester_DecodedExplainer(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("info only", levelExpecter{}).Every()
+
+	wt.Write([]byte(`{"level":"ERROR"}`))
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if msg := ve.Errs[0].Errors[0].Err.Error(); msg != "unexpected level ERROR" {
+		t.Fatalf("expected explanation, got %q", msg)
+	}
+}
+
+func TestWTester_RegisterDecoderOverridesByName(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.RegisterDecoder(NewDecoder("json", func(p []byte) (map[string]any, error) {
+		return map[string]any{"level": "INFO"}, nil
+	}))
+	wt.Expect("info only", levelExpecter{}).Every()
+
+	wt.Write([]byte("not even json"))
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_RegisterDecoderOfAnotherType(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.RegisterDecoder(NewDecoder("json", func(p []byte) (string, error) {
+		return string(p), nil
+	}))
+	wt.Expect("no password", MaskPolicy().Absent("password")).Every()
+
+	wt.Write([]byte(`{"msg":"login"}`))
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	want := `decoder "json" registered as *wtester.Decoder[string], the expectation needs *wtester.Decoder[map[string]interface {}]`
+	if msg := ve.Errs[0].Errors[0].Err.Error(); msg != want {
+		t.Fatalf("expected %q, got %q", want, msg)
+	}
+}
+
+func TestWTester_DecodedExpecterOfAnotherType(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("record message", mismatchedExpecter{}).Every()
+
+	wt.Write([]byte(`{"msg":"login"}`))
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	want := `decoder "record" produced *wtester.Record, the expectation does not accept it`
+	if msg := ve.Errs[0].Errors[0].Err.Error(); msg != want {
+		t.Fatalf("expected %q, got %q", want, msg)
+	}
+}
+
+// mismatchedExpecter declares the record decoder but checks strings.
+type mismatchedExpecter struct{}
+
+func (mismatchedExpecter) Expect([]byte) bool          { return false }
+func (mismatchedExpecter) Decoder() Decoding           { return RecordDecoder }
+func (mismatchedExpecter) ExpectDecoded(v string) bool { return true }
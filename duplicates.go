@@ -0,0 +1,128 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StrictJSONDecoder is a [JSONDecoder] rejecting the records with
+// duplicate keys, which [json.Unmarshal] silently resolves to the last
+// value. See [WTester.StrictJSON].
+var StrictJSONDecoder = NewDecoder("json", func(p []byte) (map[string]any, error) {
+	dups, err := duplicateKeys(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %s", err.Error())
+	}
+
+	if len(dups) > 0 {
+		return nil, fmt.Errorf("duplicate JSON keys: %s", strings.Join(dups, ", "))
+	}
+
+	return JSONDecoder.Decode(p)
+})
+
+// StrictJSON makes the WTester decode the records checked by the
+// [JSONExpecter] implementations with [StrictJSONDecoder]. Records
+// with duplicate keys are then reported once under the "json decoder"
+// title and skipped by those expectations.
+func (l *WTester) StrictJSON() *WTester {
+	l.RegisterDecoder(StrictJSONDecoder)
+	return l
+}
+
+// DuplicateKeyScanner is an Expecter matching the JSON records without
+// duplicate keys. Create it with [NoDuplicateKeys].
+type DuplicateKeyScanner struct{}
+
+// NoDuplicateKeys returns an Expecter matching the JSON records
+// where no object, at any depth, holds the same key twice, as slog
+// writes when attributes collide, e.g. {"msg":"a","msg":"b"}. Records
+// that are not valid JSON do not match.
+func NoDuplicateKeys() *DuplicateKeyScanner {
+	return &DuplicateKeyScanner{}
+}
+
+func (s *DuplicateKeyScanner) Expect(actual []byte) bool {
+	dups, err := duplicateKeys(actual)
+	return err == nil && len(dups) == 0
+}
+
+// Explain returns an error listing the paths of the duplicate keys.
+func (s *DuplicateKeyScanner) Explain(actual []byte) error {
+	dups, err := duplicateKeys(actual)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal JSON: %s", err.Error())
+	}
+
+	if len(dups) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("duplicate JSON keys: %s", strings.Join(dups, ", "))
+}
+
+// duplicateKeys returns the paths of the keys repeated in their
+// object, once each and in document order, e.g. "user.id" or
+// "items[1].id".
+func duplicateKeys(p []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	var dups []string
+	if err := scanDuplicates(dec, "", &dups); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return dups, nil
+}
+
+// scanDuplicates reads the next value of dec, appending
+// the paths of the duplicate keys found in it.
+func scanDuplicates(dec *json.Decoder, path string, dups *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]int)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			key := tok.(string)
+			keyPath := joinPath(path, key)
+			if seen[key]++; seen[key] == 2 {
+				*dups = append(*dups, keyPath)
+			}
+
+			if err := scanDuplicates(dec, keyPath, dups); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := scanDuplicates(dec, path+"["+strconv.Itoa(i)+"]", dups); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// The closing delimiter.
+	_, err = dec.Token()
+	return err
+}
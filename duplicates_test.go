@@ -0,0 +1,75 @@
+package wtester
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNoDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		record string
+		err    string
+	}{
+		"No duplicates":      {record: `{"msg":"a","user":{"id":1},"items":[{"id":1},{"id":2}]}` + "\n"},
+		"Same key in arrays": {record: `[{"id":1},{"id":2}]`},
+		"Top-level":          {record: `{"msg":"a","msg":"b","msg":"c"}`, err: "duplicate JSON keys: msg"},
+		"Nested":             {record: `{"user":{"id":1,"name":"a","id":2},"items":[{"id":1},{"id":2,"id":3}]}`, err: "duplicate JSON keys: user.id, items[1].id"},
+		"Duplicate objects":  {record: `{"a":{"b":1},"a":{"b":2,"b":3}}`, err: "duplicate JSON keys: a, a.b"},
+		"Invalid JSON":       {record: `{"msg":}`, err: "failed to unmarshal JSON: missing value after object key"},
+		"Trailing data":      {record: `{"msg":"a"} {}`, err: "failed to unmarshal JSON: invalid character after top-level value"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, NoDuplicateKeys()).Every()
+
+			if _, err := wt.Write([]byte(tt.record)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestWTester_StrictJSON(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).StrictJSON()
+	wt.Expect("No password", MaskPolicy().Absent("password")).Every()
+
+	logger := slog.New(slog.NewJSONHandler(wt, nil))
+	logger.Info("login", "msg", "shadowed")
+	logger.Info("logout")
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok || len(ve.Errs) != 1 || ve.Errs[0].Title != "json decoder" {
+		t.Fatalf("expected json decoder errors only, got %v", ve)
+	}
+
+	if got := ve.Errs[0].Errors[0].Err.Error(); !strings.HasPrefix(got, "duplicate JSON keys: msg") {
+		t.Fatalf("expected duplicate msg, got %q", got)
+	}
+}
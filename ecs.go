@@ -0,0 +1,225 @@
+package wtester
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ecsFieldsJSON is the catalog of the fields of the core ECS field
+// sets, by flattened name, with their Elasticsearch field type.
+//
+//go:embed ecs_fields.json
+var ecsFieldsJSON []byte
+
+var (
+	ecsFields    map[string]string
+	ecsTopLevels = make(map[string]bool)
+)
+
+func init() {
+	if err := json.Unmarshal(ecsFieldsJSON, &ecsFields); err != nil {
+		panic("wtester: invalid ECS catalog: " + err.Error())
+	}
+
+	for name := range ecsFields {
+		top, _, _ := strings.Cut(name, ".")
+		ecsTopLevels[top] = true
+	}
+}
+
+// ECSFieldType returns the Elasticsearch type of an ECS field by
+// flattened name, e.g. "long" for "http.response.status_code", and
+// whether the field is in the catalog of the core field sets.
+func ECSFieldType(name string) (string, bool) {
+	typ, ok := ecsFields[name]
+	return typ, ok
+}
+
+// ECSConformance is a [JSONExpecter] checking the records against
+// the Elastic Common Schema. Create it with [ECSCheck].
+type ECSConformance struct {
+	allowed []string
+}
+
+// ECSCheck returns an Expecter matching the JSON records that comply
+// with the Elastic Common Schema: the "@timestamp" and "ecs.version"
+// fields are present, every top-level field belongs to an ECS field
+// set and the values of the fields in the catalog, see [ECSFieldType],
+// have the type of their mapping, e.g. a number for
+// "http.response.status_code". Like Elasticsearch, numbers and
+// booleans are accepted for the keyword and text mappings, e.g. a
+// number for "error.code", but strings are not accepted for the
+// number, date and ip ones. Fields can be nested objects or dotted
+// keys, and arrays of values are checked value by value. The fields
+// of the ECS field sets that are not in the catalog are not checked.
+func ECSCheck() *ECSConformance {
+	return &ECSConformance{}
+}
+
+// Allow accepts the given custom top-level fields, e.g. "app".
+// Their content is not checked.
+func (c *ECSConformance) Allow(fields ...string) *ECSConformance {
+	c.allowed = append(c.allowed, fields...)
+	return c
+}
+
+// Only for satisfy the Expecter interface.
+func (c *ECSConformance) Expect(actual []byte) bool {
+	return false
+}
+
+func (c *ECSConformance) ExpectJSON(m map[string]any) bool {
+	return len(c.violations(m)) == 0
+}
+
+// ExplainJSON returns an error describing every field
+// that does not comply with ECS.
+func (c *ECSConformance) ExplainJSON(m map[string]any) error {
+	return errors.Join(c.violations(m)...)
+}
+
+func (c *ECSConformance) violations(m map[string]any) []error {
+	fields := make(map[string]any)
+	flattenECS("", m, fields)
+
+	var errs []error
+	for _, required := range []string{"@timestamp", "ecs.version"} {
+		if _, ok := fields[required]; !ok {
+			errs = append(errs, fmt.Errorf("missing %s", required))
+		}
+	}
+
+	unknown := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		top, _, _ := strings.Cut(name, ".")
+		if !ecsTopLevels[top] {
+			if !slices.Contains(c.allowed, top) && !unknown[top] {
+				unknown[top] = true
+				errs = append(errs, fmt.Errorf("unknown top-level field %q", top))
+			}
+			continue
+		}
+
+		typ, ok := ecsFields[name]
+		if ok && !ecsTypeOf(typ, fields[name]) {
+			errs = append(errs, fmt.Errorf("%q: expected %s, got %s", name, typ, jsonKind(fields[name])))
+		}
+	}
+
+	return errs
+}
+
+// flattenECS adds the fields of m to fields by flattened name. Objects
+// are walked down to the fields of the catalog that are not objects.
+func flattenECS(prefix string, m map[string]any, fields map[string]any) {
+	for k, v := range m {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+
+		inner, isObject := v.(map[string]any)
+		if _, known := ecsFields[name]; isObject && !known {
+			flattenECS(name, inner, fields)
+			continue
+		}
+
+		fields[name] = v
+	}
+}
+
+// ecsTypeOf reports whether v can be indexed as the Elasticsearch type.
+func ecsTypeOf(typ string, v any) bool {
+	if values, ok := v.([]any); ok && typ != "geo_point" && typ != "nested" {
+		for _, e := range values {
+			if !ecsTypeOf(typ, e) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch v := v.(type) {
+	case nil:
+		// Elasticsearch ignores null values.
+		return true
+	case string:
+		switch typ {
+		case "keyword", "constant_keyword", "wildcard", "text", "match_only_text":
+			return true
+		case "date":
+			_, err := time.Parse(time.RFC3339Nano, v)
+			return err == nil
+		case "ip":
+			_, err := netip.ParseAddr(v)
+			return err == nil
+		case "geo_point":
+			return true
+		}
+	case float64:
+		switch typ {
+		case "keyword", "constant_keyword", "wildcard", "text", "match_only_text":
+			// Indexed as their string representation.
+			return true
+		case "long", "integer":
+			return v == float64(int64(v))
+		case "float", "scaled_float", "date":
+			return true
+		}
+	case bool:
+		switch typ {
+		case "boolean", "keyword", "constant_keyword", "wildcard", "text", "match_only_text":
+			return true
+		}
+	case map[string]any:
+		switch typ {
+		case "object", "flattened", "nested":
+			return true
+		case "geo_point":
+			_, lat := v["lat"].(float64)
+			_, lon := v["lon"].(float64)
+			return lat && lon
+		}
+	case []any:
+		switch typ {
+		case "geo_point":
+			return len(v) == 2 && ecsTypeOf("float", v)
+		case "nested":
+			for _, e := range v {
+				if _, ok := e.(map[string]any); !ok {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonKind describes a JSON value, e.g. `string "200"`.
+func jsonKind(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64:
+		return "number " + toString(v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
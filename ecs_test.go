@@ -0,0 +1,109 @@
+package wtester
+
+import (
+	"io"
+	"testing"
+)
+
+func TestECSCheck(t *testing.T) {
+	t.Parallel()
+
+	const base = `"@timestamp":"2024-05-01T10:00:00.000Z","ecs.version":"8.11.0"`
+
+	tests := map[string]struct {
+		exp    *ECSConformance
+		record string
+		err    string
+	}{
+		"Dotted keys": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"log.level":"info","message":"started","http.response.status_code":200,"source.ip":"10.0.0.1"}`,
+		},
+		"Nested objects": {
+			exp:    ECSCheck(),
+			record: `{"@timestamp":1714557600000,"ecs":{"version":"8.11.0"},"http":{"request":{"method":"GET","bytes":12}},"labels":{"env":"prod"},"tags":["a","b"],"host":{"geo":{"location":{"lat":1.5,"lon":2}}}}`,
+		},
+		"Group, organization and package fields": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"group":{"name":"admins"},"organization.name":"acme","package":{"name":"openssl","size":1024}}`,
+		},
+		"Custom fields in a field set": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"http":{"custom":{"a":1}}}`,
+		},
+		"Allowed top-level field": {
+			exp:    ECSCheck().Allow("app"),
+			record: `{` + base + `,"app":{"tenant":"a"}}`,
+		},
+		"Numbers and booleans as keywords": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"error":{"code":500},"tags":["a",1,true],"labels":{"retry":true}}`,
+		},
+		"Missing required fields": {
+			exp:    ECSCheck(),
+			record: `{"message":"started"}`,
+			err:    "missing @timestamp\nmissing ecs.version",
+		},
+		"Status code as a string": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"http":{"response":{"status_code":"200"}}}`,
+			err:    `"http.response.status_code": expected long, got string "200"`,
+		},
+		"Wrong types": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"source.ip":"localhost","process.pid":1.5,"event.start":"yesterday","tags":["a",{"b":1}],"log.level":{"name":"info"}}`,
+			err: `"event.start": expected date, got string "yesterday"
+"log.level": expected keyword, got object
+"process.pid": expected long, got number 1.5
+"source.ip": expected ip, got string "localhost"
+"tags": expected keyword, got array`,
+		},
+		"Unknown top-level fields": {
+			exp:    ECSCheck(),
+			record: `{` + base + `,"msg":"started","app":{"a":1,"b":2}}`,
+			err:    "unknown top-level field \"app\"\nunknown top-level field \"msg\"",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, tt.exp).Every()
+
+			if _, err := wt.Write([]byte(tt.record)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestECSFieldType(t *testing.T) {
+	t.Parallel()
+
+	if typ, ok := ECSFieldType("http.response.status_code"); !ok || typ != "long" {
+		t.Fatalf("expected long, got %q %v", typ, ok)
+	}
+
+	if _, ok := ECSFieldType("http.response.status"); ok {
+		t.Fatal("expected http.response.status not to be in the catalog")
+	}
+}
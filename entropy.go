@@ -0,0 +1,235 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+)
+
+// TokenProfile is a character class of the tokens checked by
+// [HighEntropy], each one with its own entropy threshold.
+type TokenProfile string
+
+const (
+	// ProfileHex are the tokens made of hexadecimal digits only.
+	ProfileHex TokenProfile = "hex"
+	// ProfileAlphanumeric are the tokens made of letters and digits
+	// that are not hexadecimal.
+	ProfileAlphanumeric TokenProfile = "alphanumeric"
+	// ProfileBase64 are the tokens using any other character of the
+	// base64 standard and URL alphabets.
+	ProfileBase64 TokenProfile = "base64"
+)
+
+// Known-safe random-looking tokens. [HighEntropy] allows UUIDs anywhere
+// by default, but trace and span IDs only as the values of their keys,
+// see [EntropyScanner.Allow] to allow them anywhere.
+const (
+	PatternUUID    = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+	PatternTraceID = `^[0-9a-f]{32}$`
+	PatternSpanID  = `^[0-9a-f]{16}$`
+)
+
+// tokenSeparators splits a record into the tokens that could be secrets,
+// anything that is not in the base64 alphabets, padding included,
+// ends a token.
+var tokenSeparators = regexp.MustCompile(`[^A-Za-z0-9+/_-]+`)
+
+// traceKeyValue matches a text ending with a trace or span ID key and
+// the separator of its value, e.g. `trace_id=` or `"spanId":"`.
+var traceKeyValue = regexp.MustCompile(`(?i)(?:trace|span)[._-]?id"?\s*[=:]\s*"?$`)
+
+// EntropyScanner is an [Expecter] that matches the records free of
+// high-entropy tokens. Create it with [HighEntropy].
+type EntropyScanner struct {
+	minLength  int
+	thresholds map[TokenProfile]float64
+	allowed    []*regexp.Regexp
+	// tracing are allowed as the values of trace and span ID keys.
+	tracing []*regexp.Regexp
+}
+
+// HighEntropy returns an Expecter that splits every record into tokens
+// and flags the ones at least 20 characters long whose Shannon entropy,
+// in bits per character, is above the threshold of their profile: 3.0
+// for [ProfileHex], 4.2 for [ProfileAlphanumeric] and 4.5 for
+// [ProfileBase64]. It matches the records where nothing is flagged, use
+// it with [Expect.Every].
+//
+// It catches the secrets that cannot be registered ahead of time, like
+// randomly generated API keys, see [WTester.ForbidSecrets] for the ones
+// that can. UUIDs are allowed by default, and so are trace IDs and span
+// IDs when they are the values of keys such as "trace_id" or "spanId",
+// a 32 hex digits API key logged under another key is flagged.
+func HighEntropy() *EntropyScanner {
+	return &EntropyScanner{
+		minLength: 20,
+		thresholds: map[TokenProfile]float64{
+			ProfileHex:          3.0,
+			ProfileAlphanumeric: 4.2,
+			ProfileBase64:       4.5,
+		},
+		allowed: []*regexp.Regexp{
+			regexp.MustCompile(PatternUUID),
+		},
+		tracing: []*regexp.Regexp{
+			regexp.MustCompile(PatternTraceID),
+			regexp.MustCompile(PatternSpanID),
+		},
+	}
+}
+
+// WithMinLength sets the length of the shortest token checked.
+func (s *EntropyScanner) WithMinLength(n int) *EntropyScanner {
+	s.minLength = n
+	return s
+}
+
+// WithThreshold sets the entropy, in bits per character, above which
+// a token of the profile is flagged.
+func (s *EntropyScanner) WithThreshold(profile TokenProfile, bits float64) *EntropyScanner {
+	s.thresholds[profile] = bits
+	return s
+}
+
+// Profiles restricts the check to the tokens of the given profiles.
+func (s *EntropyScanner) Profiles(profiles ...TokenProfile) *EntropyScanner {
+	for p := range s.thresholds {
+		if !slices.Contains(profiles, p) {
+			delete(s.thresholds, p)
+		}
+	}
+
+	return s
+}
+
+// Allow ignores the tokens fully matching any of the given regular
+// expressions. Panics if a pattern does not compile.
+func (s *EntropyScanner) Allow(patterns ...string) *EntropyScanner {
+	for _, p := range patterns {
+		s.allowed = append(s.allowed, regexp.MustCompile(p))
+	}
+
+	return s
+}
+
+func (s *EntropyScanner) Expect(actual []byte) bool {
+	return len(s.scan(actual)) == 0
+}
+
+// Explain returns an error describing every flagged token, redacted,
+// with its profile, entropy and offset in the record.
+func (s *EntropyScanner) Explain(actual []byte) error {
+	var errs []error
+	for _, f := range s.scan(actual) {
+		errs = append(errs, f)
+	}
+
+	return errors.Join(errs...)
+}
+
+type entropyFinding struct {
+	token   string
+	profile TokenProfile
+	entropy float64
+	offset  int
+}
+
+func (f entropyFinding) Error() string {
+	return fmt.Sprintf(
+		"high entropy %s token %q at offset %d (%.2f bits per character)",
+		f.profile, redactToken(f.token), f.offset, f.entropy,
+	)
+}
+
+func (s *EntropyScanner) scan(actual []byte) []entropyFinding {
+	var findings []entropyFinding
+
+	start := 0
+	bounds := append(tokenSeparators.FindAllIndex(actual, -1), []int{len(actual), len(actual)})
+	for _, sep := range bounds {
+		token := string(actual[start:sep[0]])
+		offset := start
+		start = sep[1]
+
+		if len(token) < s.minLength {
+			continue
+		}
+
+		profile := tokenProfile(token)
+		threshold, ok := s.thresholds[profile]
+		if !ok {
+			continue
+		}
+
+		matches := func(re *regexp.Regexp) bool {
+			return re.MatchString(token)
+		}
+		if slices.ContainsFunc(s.allowed, matches) {
+			continue
+		}
+
+		if slices.ContainsFunc(s.tracing, matches) && traceKeyValue.Match(actual[:offset]) {
+			continue
+		}
+
+		if e := shannonEntropy(token); e > threshold {
+			findings = append(findings, entropyFinding{
+				token:   token,
+				profile: profile,
+				entropy: e,
+				offset:  offset,
+			})
+		}
+	}
+
+	return findings
+}
+
+// tokenProfile returns the narrowest profile of the token.
+func tokenProfile(token string) TokenProfile {
+	hex, alnum := true, true
+	for _, r := range token {
+		isDigit := r >= '0' && r <= '9'
+		isHexLetter := (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+
+		hex = hex && (isDigit || isHexLetter)
+		alnum = alnum && (isDigit || isLetter)
+	}
+
+	switch {
+	case hex:
+		return ProfileHex
+	case alnum:
+		return ProfileAlphanumeric
+	default:
+		return ProfileBase64
+	}
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
+
+// redactToken keeps the first four characters of a token, at most
+// half of it for short tokens.
+func redactToken(token string) string {
+	return fmt.Sprintf("%s…(%d chars)", token[:min(4, len(token)/2)], len(token))
+}
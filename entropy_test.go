@@ -0,0 +1,95 @@
+package wtester
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHighEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		scanner  *EntropyScanner
+		input    string
+		expected string
+	}{
+		"Plain log line": {
+			input: `{"level":"INFO","msg":"internationalization finished","duration":"1.5s"}`,
+		},
+		"UUID and trace ID are allowed": {
+			input: `{"req_id":"56de9ab2-bacd-4e24-a3c7-8221860d8edc","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`,
+		},
+		"Span ID in logfmt": {
+			input: `trace.id=4bf92f3577b34da6a3ce929d0e0e4736 spanId=00f067aa0ba902b7 msg=served`,
+		},
+		"Hex API key shaped like a trace ID": {
+			input:    `api_key=8f14e45fceea167a5a36dedd4bea2543`,
+			expected: `high entropy hex token "8f14…(32 chars)" at offset 8`,
+		},
+		"Trace IDs allowed anywhere": {
+			scanner: HighEntropy().Allow(PatternTraceID),
+			input:   `api_key=8f14e45fceea167a5a36dedd4bea2543`,
+		},
+		"Random API key": {
+			input:    `{"msg":"calling api","key":"Zx8QpL2vR7mK4tN9wB3yH6jD1sF5gC0a"}`,
+			expected: `high entropy alphanumeric token "Zx8Q…(32 chars)" at offset 28`,
+		},
+		"Base64 secret": {
+			input:    `secret=q9/Hk+2Lw7Zp4X+vN8bR1mT6yJ3cF0dG5sA=`,
+			expected: `high entropy base64 token "q9/H…(35 chars)" at offset 7`,
+		},
+		"Hex key": {
+			input:    `key=9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08`,
+			expected: `high entropy hex token "9f86…(64 chars)" at offset 4`,
+		},
+		"Custom allowlist": {
+			scanner: HighEntropy().Allow(`^Zx8Q`),
+			input:   `{"key":"Zx8QpL2vR7mK4tN9wB3yH6jD1sF5gC0a"}`,
+		},
+		"Disabled profile": {
+			scanner: HighEntropy().Profiles(ProfileBase64),
+			input:   `{"key":"Zx8QpL2vR7mK4tN9wB3yH6jD1sF5gC0a"}`,
+		},
+		"Short token": {
+			scanner:  HighEntropy().WithMinLength(3).WithThreshold(ProfileAlphanumeric, 1.0),
+			input:    `xyz`,
+			expected: `high entropy alphanumeric token "x…(3 chars)" at offset 0`,
+		},
+		"Short tokens are ignored": {
+			scanner: HighEntropy().WithMinLength(40),
+			input:   `{"key":"Zx8QpL2vR7mK4tN9wB3yH6jD1sF5gC0a"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			scanner := tt.scanner
+			if scanner == nil {
+				scanner = HighEntropy()
+			}
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, scanner).Every()
+			wt.Write([]byte(tt.input))
+
+			err := wt.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			explanation := ve.Errs[0].Errors[0].Err.Error()
+			if !strings.HasPrefix(explanation, tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, explanation)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package wtester
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // ValidationErrors is a struct that holds a list of
@@ -38,6 +39,8 @@ type ExpectError struct {
 func (v ExpectError) Error() string {
 	errs := ""
 	for _, e := range v.Errors {
+		errs += e.streamPrefix()
+
 		if len(e.Bytes) != 0 {
 			errs += string(e.Bytes) + "\n"
 		}
@@ -45,19 +48,36 @@ func (v ExpectError) Error() string {
 		if e.Err != nil {
 			errs += e.Err.Error() + "\n"
 		}
+
+		if e.Caller != "" {
+			errs += "at " + e.Caller + "\n"
+		}
 	}
 	return fmt.Sprintf("validation \"%s\"\nFails On:\n%s", v.Title, errs)
 }
 
 // ErrorRecord is a struct that holds the bytes that
 // failed validation and the error that was returned.
+// Caller is the file:line of the statement that wrote
+// the bytes, only set when [WTester.WithCallers] is enabled.
+// Stream is the name of the stream the bytes were written
+// to, empty for bytes written directly to the [WTester].
+// RuntimeStream and Time are the stream and the timestamp
+// set by the container runtime, only set when
+// [WTester.UnwrapContainerLogs] is enabled.
 type ErrorRecord struct {
-	Bytes []byte
-	Err   error
+	Bytes         []byte
+	Err           error
+	Caller        string
+	Stream        string
+	RuntimeStream string
+	Time          time.Time
 }
 
 func (e ErrorRecord) Error() string {
 	errs := ""
+	errs += e.streamPrefix()
+
 	if e.Err != nil {
 		errs += e.Err.Error() + "\n"
 	}
@@ -66,5 +86,22 @@ func (e ErrorRecord) Error() string {
 		errs += string(e.Bytes) + "\n"
 	}
 
+	if e.Caller != "" {
+		errs += "at " + e.Caller + "\n"
+	}
+
 	return errs
 }
+
+// streamPrefix returns the "[stream] " prefix of the record, with
+// the runtime stream if it differs, e.g. "[pod-a stderr] ".
+func (e ErrorRecord) streamPrefix() string {
+	switch {
+	case e.Stream == "":
+		return ""
+	case e.RuntimeStream != "" && e.RuntimeStream != e.Stream:
+		return "[" + e.Stream + " " + e.RuntimeStream + "] "
+	}
+
+	return "[" + e.Stream + "] "
+}
@@ -1,6 +1,9 @@
 package wtester
 
-import "sync"
+import (
+	"slices"
+	"sync"
+)
 
 type Expect struct {
 	title   string
@@ -9,6 +12,7 @@ type Expect struct {
 	min     int
 	max     int
 	noMatch bool
+	streams []string
 	matches int
 	mu      sync.Mutex // guards matches
 }
@@ -54,6 +58,20 @@ func (e *Expect) Every() *Expect {
 	return e
 }
 
+// OnStreams scopes the expectation to the records written to the
+// named streams, see [WTester.Stream]. Records written to any other
+// stream, including the ones written directly to the WTester, are
+// ignored by the expectation. By default every record is checked.
+func (e *Expect) OnStreams(names ...string) *Expect {
+	e.streams = append(e.streams, names...)
+	return e
+}
+
+// onStream reports whether the expectation applies to the stream.
+func (e *Expect) onStream(stream string) bool {
+	return len(e.streams) == 0 || slices.Contains(e.streams, stream)
+}
+
 func (e *Expect) matched() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -14,12 +14,27 @@ type Expecter interface {
 
 // JSONExpecter is an interface for JSON expectations.
 // If implemented, the expectation will be unmarshaled into a map
-// before being passed to ExpectJSON instead of calling the Expect method.
+// by [JSONDecoder] before being passed to ExpectJSON instead of
+// calling the Expect method.
 // Improves performance by unmarshaling the JSON data only once per Write call.
 type JSONExpecter interface {
 	ExpectJSON(actual map[string]any) bool
 }
 
+// Explainer is an optional interface for expecters that can tell
+// why a record did not match. Explain is only called for the records
+// that failed an [Expect.Every] expectation, and the returned error is
+// attached to their [ErrorRecord].
+type Explainer interface {
+	Explain(actual []byte) error
+}
+
+// JSONExplainer is the [Explainer] counterpart of [JSONExpecter],
+// it receives the record already unmarshaled.
+type JSONExplainer interface {
+	ExplainJSON(actual map[string]any) error
+}
+
 type ExpectFunc func(actual []byte) bool
 
 func (f ExpectFunc) Expect(actual []byte) bool {
@@ -101,6 +116,10 @@ type obfuscatedMatch struct {
 //
 // Panics if the percentageObfuscated is not between 0 and 1 or if
 // the fields slice is empty.
+//
+// Deprecated: ObfuscatedMatch counts bytes instead of characters and
+// passes as soon as any of the fields is obfuscated enough. Use
+// [MaskPolicy], which checks every field against an explicit rule.
 func ObfuscatedMatch(
 	obfuscateChar string,
 	percentageObfuscated float64,
@@ -0,0 +1,151 @@
+package wtester
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+)
+
+// CaptureOption configures [WTester.CaptureGlobal].
+type CaptureOption func(*captureConfig)
+
+type captureConfig struct {
+	stdout bool
+	stderr bool
+}
+
+// CaptureStdout makes [WTester.CaptureGlobal] also capture everything
+// written to [os.Stdout] into the [StreamStdout] stream.
+func CaptureStdout() CaptureOption {
+	return func(c *captureConfig) {
+		c.stdout = true
+	}
+}
+
+// CaptureStderr makes [WTester.CaptureGlobal] also capture everything
+// written to [os.Stderr] into the [StreamStderr] stream.
+func CaptureStderr() CaptureOption {
+	return func(c *captureConfig) {
+		c.stderr = true
+	}
+}
+
+// CaptureGlobal points the process-global loggers at the WTester until
+// the test finishes: the output of the standard [log] package and the
+// default [slog.Logger], which is replaced by one with a JSON handler.
+// With [CaptureStdout] and [CaptureStderr], [os.Stdout] and [os.Stderr]
+// are replaced by pipes read line by line into the corresponding stream.
+//
+// Everything is restored on the test cleanup. Loggers obtained while
+// capturing, e.g. by goroutines leaked by the test, can keep being used
+// after the cleanup, their output is then dropped.
+//
+// As it changes process-global state, it must not be used in parallel
+// tests.
+func (l *WTester) CaptureGlobal(t testing.TB, opts ...CaptureOption) {
+	t.Helper()
+
+	cfg := &captureConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var restores []func()
+
+	if cfg.stdout {
+		restores = append(restores, captureFile(t, &os.Stdout, l.Stream(StreamStdout)))
+	}
+
+	if cfg.stderr {
+		restores = append(restores, captureFile(t, &os.Stderr, l.Stream(StreamStderr)))
+	}
+
+	gate := &gatedWriter{w: l}
+
+	prevSlog := slog.Default()
+	prevOutput := log.Writer()
+	prevFlags := log.Flags()
+	prevPrefix := log.Prefix()
+
+	// slog.SetDefault redirects the log package to the new handler,
+	// set the log output afterwards to keep its own format.
+	slog.SetDefault(slog.New(slog.NewJSONHandler(gate, nil)))
+	log.SetOutput(gate)
+	log.SetFlags(prevFlags)
+
+	t.Cleanup(func() {
+		for _, restore := range restores {
+			restore()
+		}
+
+		slog.SetDefault(prevSlog)
+		log.SetOutput(prevOutput)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+
+		gate.close()
+	})
+}
+
+// captureFile replaces *f with the write end of a pipe whose read end
+// is copied line by line to w. The returned function puts back the
+// original file and waits for everything written to be copied.
+func captureFile(t testing.TB, f **os.File, w io.Writer) (restore func()) {
+	t.Helper()
+
+	r, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("wtester: failed to create pipe: %v", err)
+	}
+
+	orig := *f
+	*f = pw
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		lw := &lineWriter{w: w}
+		_, _ = io.Copy(lw, r)
+		_ = lw.Flush()
+	}()
+
+	return func() {
+		*f = orig
+
+		// Writers still holding the pipe get an error from now on.
+		_ = pw.Close()
+		<-done
+		_ = r.Close()
+	}
+}
+
+// gatedWriter forwards writes to w until it is closed,
+// writes after that are dropped.
+type gatedWriter struct {
+	mu     sync.RWMutex // guards closed
+	w      io.Writer
+	closed bool
+}
+
+func (g *gatedWriter) Write(p []byte) (n int, err error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.closed {
+		return len(p), nil
+	}
+
+	return g.w.Write(p)
+}
+
+// close waits for the in-flight writes and drops the following ones.
+func (g *gatedWriter) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.closed = true
+}
@@ -0,0 +1,53 @@
+package wtester
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+// Not parallel, it changes process-global state.
+func TestWTester_CaptureGlobalRedirectsAndRestores(t *testing.T) {
+	origStdout, origStderr := os.Stdout, os.Stderr
+	origLogOutput, origSlog := log.Writer(), slog.Default()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("log line", StringMatch("from log", false)).WithMin(1).WithMax(1)
+	wt.Expect("slog line", StringMatch(`"msg":"from slog"`, false)).WithMin(1).WithMax(1)
+	wt.Expect("stdout line", StringMatch("from stdout\n", true)).WithMin(1).WithMax(1).OnStreams(StreamStdout)
+	wt.Expect("stderr line", StringMatch("from stderr\n", true)).WithMin(1).WithMax(1).OnStreams(StreamStderr)
+	wt.Expect("nothing after restore", StringMatch("leaked", false)).WithMax(0)
+
+	var leaked *slog.Logger
+	t.Run("capture", func(t *testing.T) {
+		wt.CaptureGlobal(t, CaptureStdout(), CaptureStderr())
+
+		log.Print("from log")
+		slog.Info("from slog")
+		fmt.Println("from stdout")
+		fmt.Fprintln(os.Stderr, "from stderr")
+
+		leaked = slog.Default()
+	})
+
+	leaked.Info("leaked")
+
+	if os.Stdout != origStdout || os.Stderr != origStderr {
+		t.Fatalf("expected std streams to be restored")
+	}
+
+	if log.Writer() != origLogOutput {
+		t.Fatalf("expected log output to be restored")
+	}
+
+	if slog.Default() != origSlog {
+		t.Fatalf("expected default slog logger to be restored")
+	}
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package wtester
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// defaultMaxGroupLines is the default maximum number of lines of a
+// group, see [Grouper.WithMaxLines].
+const defaultMaxGroupLines = 1000
+
+// Grouper groups multi-line records, like stack traces, before the
+// expectations run. Create it with [GroupByStart] or [GroupByIndent]
+// and set it with [WTester.GroupRecords].
+type Grouper struct {
+	start    *regexp.Regexp
+	maxLines int
+	timeout  time.Duration
+
+	check  func(rec *rawRecord)
+	groups map[groupKey]*recordGroup
+	mu     sync.Mutex // guards groups
+}
+
+// groupKey identifies the stream a group belongs to. The runtime
+// stream keeps the stdout and stderr of an unwrapped container log
+// apart, see [WTester.UnwrapContainerLogs].
+type groupKey struct {
+	stream        string
+	runtimeStream string
+}
+
+// recordGroup is the open group of a stream.
+type recordGroup struct {
+	rec   *rawRecord
+	lines int
+	timer *time.Timer
+}
+
+// GroupByStart returns a Grouper where every line matching the
+// pattern starts a new record, e.g. `^\d{4}-\d{2}-\d{2}` for records
+// starting with a date, and any other line continues the previous one.
+// Panics if the pattern does not compile.
+func GroupByStart(pattern string) *Grouper {
+	return newGrouper(regexp.MustCompile(pattern))
+}
+
+// GroupByIndent returns a Grouper where the lines starting with a
+// space or a tab continue the previous record, e.g. the "\tat ..."
+// frames of a Java stack trace, and any other line starts a new one.
+func GroupByIndent() *Grouper {
+	return newGrouper(regexp.MustCompile(`^[^ \t]`))
+}
+
+func newGrouper(start *regexp.Regexp) *Grouper {
+	return &Grouper{
+		start:    start,
+		maxLines: defaultMaxGroupLines,
+		groups:   make(map[groupKey]*recordGroup),
+	}
+}
+
+// WithMaxLines sets the maximum number of lines of a record. A record
+// reaching it is checked right away and the following continuation
+// lines start a new one. Defaults to 1000.
+func (g *Grouper) WithMaxLines(n int) *Grouper {
+	g.maxLines = n
+	return g
+}
+
+// WithFlushTimeout sets how long a record waits for continuation
+// lines before being checked. By default, a record is checked when
+// the next one starts or when Validate is called.
+func (g *Grouper) WithFlushTimeout(d time.Duration) *Grouper {
+	g.timeout = d
+	return g
+}
+
+// GroupRecords makes the WTester group the lines written to it into
+// multi-line records before checking them against the expectations,
+// so that a stack trace is a single record and "every record starts
+// with a timestamp" holds. Each line of a Write is grouped on its own,
+// and each stream is grouped apart. The bytes are still written to the
+// underlying writer right away.
+//
+// A Grouper holds the state of the open records, it must not be
+// shared between WTesters.
+func (l *WTester) GroupRecords(g *Grouper) *WTester {
+	g.check = l.check
+	l.grouper = g
+	return l
+}
+
+// feed adds the lines of the written record to the open groups.
+func (g *Grouper) feed(rec *rawRecord) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := groupKey{stream: rec.stream, runtimeStream: rec.runtimeStream}
+	for p := rec.p; len(p) > 0; {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+		}
+		p = p[len(line):]
+
+		grp := g.groups[key]
+		if grp == nil || g.start.Match(line) || grp.lines >= g.maxLines {
+			g.flushLocked(key)
+
+			grp = &recordGroup{
+				rec: &rawRecord{
+					stream:        rec.stream,
+					runtimeStream: rec.runtimeStream,
+					time:          rec.time,
+					pcs:           rec.pcs,
+				},
+			}
+			g.groups[key] = grp
+		}
+
+		grp.rec.p = append(grp.rec.p, line...)
+		grp.lines++
+
+		if g.timeout > 0 {
+			if grp.timer != nil {
+				grp.timer.Stop()
+			}
+
+			grp.timer = time.AfterFunc(g.timeout, func() {
+				g.mu.Lock()
+				defer g.mu.Unlock()
+
+				if g.groups[key] == grp {
+					g.flushLocked(key)
+				}
+			})
+		}
+	}
+}
+
+// flush checks every open record.
+func (g *Grouper) flush() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key := range g.groups {
+		g.flushLocked(key)
+	}
+}
+
+// flushLocked checks the open record of the stream, if any.
+func (g *Grouper) flushLocked(key groupKey) {
+	grp := g.groups[key]
+	if grp == nil {
+		return
+	}
+
+	if grp.timer != nil {
+		grp.timer.Stop()
+	}
+
+	delete(g.groups, key)
+	g.check(grp.rec)
+}
+
+// reset drops the open records without checking them.
+func (g *Grouper) reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, grp := range g.groups {
+		if grp.timer != nil {
+			grp.timer.Stop()
+		}
+
+		delete(g.groups, key)
+	}
+}
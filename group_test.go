@@ -0,0 +1,97 @@
+package wtester
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const javaLogs = `2024-01-02 12:30:45 INFO starting
+2024-01-02 12:30:46 ERROR request failed
+java.lang.IllegalStateException: boom
+	at com.acme.Service.handle(Service.java:42)
+	at com.acme.Server.run(Server.java:17)
+Caused by: java.io.IOException: closed
+	at com.acme.Conn.read(Conn.java:9)
+2024-01-02 12:30:47 INFO stopped
+`
+
+func TestWTester_GroupRecordsByStart(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).GroupRecords(GroupByStart(`^\d{4}-\d{2}-\d{2} `))
+	wt.Expect("every record starts with a timestamp", RegexMatch(`^\d{4}-\d{2}-\d{2} `)).Every()
+	wt.Expect("three records", StringMatch("", false)).WithMin(3).WithMax(3)
+	wt.Expect("trace is part of the error", RegexMatch(`(?s)ERROR.*Caused by.*Conn\.java`)).WithMin(1)
+
+	if err := wt.Scan(strings.NewReader(javaLogs)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_GroupRecordsByIndent(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).GroupRecords(GroupByIndent())
+	wt.Expect("records", StringMatch("", false)).WithMin(5).WithMax(5)
+
+	// A single write holding every line.
+	io.WriteString(wt, javaLogs)
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_GroupRecordsMaxLines(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).GroupRecords(GroupByIndent().WithMaxLines(2))
+	wt.Expect("records", StringMatch("", false)).WithMin(3).WithMax(3)
+
+	io.WriteString(wt, "error\n\tat a\n\tat b\n\tat c\n\tat d\n")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_GroupRecordsFlushTimeout(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).GroupRecords(GroupByIndent().WithFlushTimeout(10 * time.Millisecond))
+	wt.Expect("no errors", Not(StringMatch("error", false))).Every()
+
+	io.WriteString(wt, "error\n\tat a\n")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		wt.muErr.Lock()
+		flushed := len(wt.errors) > 0
+		wt.muErr.Unlock()
+
+		if flushed {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the record to be flushed after the timeout")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	if rec := string(ve.Errs[0].Errors[0].Bytes); rec != "error\n\tat a\n" {
+		t.Fatalf("expected the grouped record, got %q", rec)
+	}
+}
@@ -0,0 +1,384 @@
+package wtester
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// OTLPLogsPath is the path of the OTLP/HTTP logs endpoint.
+const OTLPLogsPath = "/v1/logs"
+
+// IngestServer is an HTTP log collector feeding the records of the
+// batches it receives to a WTester, on the stream named after it. It
+// accepts NDJSON bodies, one record per line, and OTLP/HTTP JSON
+// ExportLogsServiceRequest bodies posted to [OTLPLogsPath], both
+// optionally gzip encoded. Create it with [ListenHTTP].
+//
+// OTLP log records are flattened into one JSON record each:
+//
+//	{"time":"2024-05-01T10:00:00Z","observed_time":"...","severity_number":9,
+//	"severity_text":"INFO","body":"started","trace_id":"...","span_id":"...",
+//	"flags":1,"event_name":"...","attributes":{"http.method":"GET"},
+//	"resource":{"service.name":"api"},"scope":{"name":"...","version":"..."}}
+//
+// where the fields that are not set are omitted.
+//
+// Bodies that cannot be read are rejected with 400 Bad Request and
+// reported under the "<name> ingest" title.
+type IngestServer struct {
+	wt     *WTester
+	name   string
+	server *httptest.Server
+
+	mu       sync.Mutex // guards the fields below
+	statuses []int
+	requests int
+}
+
+// ListenHTTP starts an IngestServer on the loopback interface,
+// closed on the test cleanup.
+func ListenHTTP(t testing.TB, wt *WTester, name string) *IngestServer {
+	t.Helper()
+
+	s := &IngestServer{
+		wt:       wt,
+		name:     name,
+		statuses: []int{http.StatusOK},
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the base URL of the server, e.g. "http://127.0.0.1:4318".
+// OTLP exporters are to be configured with it as their endpoint.
+func (s *IngestServer) URL() string {
+	return s.server.URL
+}
+
+// RespondWith sets the status codes answered to the next requests, in
+// order, the last one being answered to every request after them, e.g.
+// 503, 503, 200 to test retries. The requests rejected with a 4xx
+// status, e.g. a GET or a malformed body, do not take a status code. Only the batches answered with a 2xx
+// status are fed to the WTester, as a collector drops the others.
+func (s *IngestServer) RespondWith(statuses ...int) *IngestServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(statuses) > 0 {
+		s.statuses = statuses
+	}
+
+	return s
+}
+
+// Requests returns the number of requests received so far.
+func (s *IngestServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// nextStatus returns the status code of the accepted request
+// being served.
+func (s *IngestServer) nextStatus() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+
+	return status
+}
+
+func (s *IngestServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	otlp := r.URL.Path == OTLPLogsPath
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if otlp {
+		if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+			http.Error(w, "only OTLP/HTTP JSON is supported", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		s.reject(w, body, err)
+		return
+	}
+
+	var recs [][]byte
+	if otlp {
+		if recs, err = flattenOTLP(body); err != nil {
+			s.reject(w, body, err)
+			return
+		}
+	}
+
+	// Only the accepted requests take a status, so a rejected
+	// request does not change the answer to the next ones.
+	status := s.nextStatus()
+	if status < 200 || status > 299 {
+		w.WriteHeader(status)
+		return
+	}
+
+	// The records are checked before answering, so they are
+	// all seen once the shipper got the response.
+	if !otlp {
+		writeNDJSON(s.wt.Stream(s.name), body)
+		w.WriteHeader(status)
+		return
+	}
+
+	stream := s.wt.Stream(s.name)
+	for _, rec := range recs {
+		stream.Write(rec)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte("{}"))
+}
+
+// reject answers 400 Bad Request and reports the body.
+func (s *IngestServer) reject(w http.ResponseWriter, body []byte, err error) {
+	s.wt.appendError(s.name+" ingest", ErrorRecord{
+		Bytes:  body,
+		Err:    err,
+		Stream: s.name,
+	})
+
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// readBody reads the body of the request, decompressing it if needed.
+func readBody(r *http.Request) ([]byte, error) {
+	var body io.Reader = r.Body
+
+	switch enc := r.Header.Get("Content-Encoding"); enc {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", enc)
+	}
+
+	return io.ReadAll(body)
+}
+
+// writeNDJSON writes every non-blank line of body as a record.
+func writeNDJSON(w io.Writer, body []byte) {
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			w.Write(append(line, '\n'))
+		}
+	}
+}
+
+// otlpInt is a 64-bit integer, written as a string or a number
+// in OTLP JSON.
+type otlpInt int64
+
+func (i *otlpInt) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	*i = otlpInt(v)
+	return err
+}
+
+// otlpAnyValue is the OTLP AnyValue, holding one of its fields.
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue"`
+	BoolValue   *bool    `json:"boolValue"`
+	IntValue    *otlpInt `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	BytesValue  *string  `json:"bytesValue"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue"`
+	KvlistValue *struct {
+		Values []otlpKeyValue `json:"values"`
+	} `json:"kvlistValue"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// value returns the Go value of v, bytes being base64 strings.
+func (v otlpAnyValue) value() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		values := make([]any, 0, len(v.ArrayValue.Values))
+		for _, e := range v.ArrayValue.Values {
+			values = append(values, e.value())
+		}
+		return values
+	case v.KvlistValue != nil:
+		return otlpAttributes(v.KvlistValue.Values)
+	}
+
+	return nil
+}
+
+// otlpAttributes returns the attributes as a map, nil if there are none.
+func otlpAttributes(kvs []otlpKeyValue) map[string]any {
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.value()
+	}
+
+	return m
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"scope"`
+			LogRecords []otlpLogRecord `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         otlpInt        `json:"timeUnixNano"`
+	ObservedTimeUnixNano otlpInt        `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 *otlpAnyValue  `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	Flags                uint32         `json:"flags"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+	EventName            string         `json:"eventName"`
+}
+
+// flatRecord is the JSON record of an OTLP log record,
+// see [IngestServer].
+type flatRecord struct {
+	Time           string         `json:"time,omitempty"`
+	ObservedTime   string         `json:"observed_time,omitempty"`
+	SeverityNumber int            `json:"severity_number,omitempty"`
+	SeverityText   string         `json:"severity_text,omitempty"`
+	Body           any            `json:"body,omitempty"`
+	TraceID        string         `json:"trace_id,omitempty"`
+	SpanID         string         `json:"span_id,omitempty"`
+	Flags          uint32         `json:"flags,omitempty"`
+	EventName      string         `json:"event_name,omitempty"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+	Resource       map[string]any `json:"resource,omitempty"`
+	Scope          *flatScope     `json:"scope,omitempty"`
+}
+
+type flatScope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// flattenOTLP explodes an ExportLogsServiceRequest into JSON records.
+func flattenOTLP(body []byte) ([][]byte, error) {
+	var req otlpLogsRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid ExportLogsServiceRequest: %w", err)
+	}
+
+	var recs [][]byte
+	for _, rl := range req.ResourceLogs {
+		resource := otlpAttributes(rl.Resource.Attributes)
+
+		for _, sl := range rl.ScopeLogs {
+			var scope *flatScope
+			if sl.Scope.Name != "" || sl.Scope.Version != "" {
+				scope = &flatScope{Name: sl.Scope.Name, Version: sl.Scope.Version}
+			}
+
+			for _, lr := range sl.LogRecords {
+				flat := flatRecord{
+					Time:           otlpTime(lr.TimeUnixNano),
+					ObservedTime:   otlpTime(lr.ObservedTimeUnixNano),
+					SeverityNumber: lr.SeverityNumber,
+					SeverityText:   lr.SeverityText,
+					TraceID:        lr.TraceID,
+					SpanID:         lr.SpanID,
+					Flags:          lr.Flags,
+					EventName:      lr.EventName,
+					Attributes:     otlpAttributes(lr.Attributes),
+					Resource:       resource,
+					Scope:          scope,
+				}
+
+				if lr.Body != nil {
+					flat.Body = lr.Body.value()
+				}
+
+				b, err := json.Marshal(flat)
+				if err != nil {
+					return nil, err
+				}
+				recs = append(recs, append(b, '\n'))
+			}
+		}
+	}
+
+	return recs, nil
+}
+
+// otlpTime formats a Unix time in nanoseconds, empty if unset.
+func otlpTime(ns otlpInt) string {
+	if ns == 0 {
+		return ""
+	}
+
+	return time.Unix(0, int64(ns)).UTC().Format(time.RFC3339Nano)
+}
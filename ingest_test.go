@@ -0,0 +1,236 @@
+package wtester
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const otlpRequest = `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+"scopeLogs":[{"scope":{"name":"otelslog","version":"0.1.0"},"logRecords":[
+{"timeUnixNano":"1714557600000000000","severityNumber":9,"severityText":"INFO","body":{"stringValue":"started"},
+"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174","flags":1,
+"attributes":[{"key":"port","value":{"intValue":"8080"}},{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"},{"boolValue":true}]}}}]},
+{"timeUnixNano":1714557601000000000,"severityNumber":17,"severityText":"ERROR","body":{"kvlistValue":{"values":[{"key":"code","value":{"doubleValue":1.5}}]}}}
+]}]}]}`
+
+func TestIngestServer(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path     string
+		header   http.Header
+		body     string
+		gzip     bool
+		statuses []int
+		status   int
+		want     []string
+		err      string
+	}{
+		"NDJSON": {
+			body:   "{\"msg\":\"a\"}\n\n{\"msg\":\"b\"}",
+			status: http.StatusOK,
+			want:   []string{"{\"msg\":\"a\"}\n", "{\"msg\":\"b\"}\n"},
+		},
+		"Gzip NDJSON": {
+			header: http.Header{"Content-Encoding": {"gzip"}},
+			body:   "{\"msg\":\"a\"}\n",
+			gzip:   true,
+			status: http.StatusOK,
+			want:   []string{"{\"msg\":\"a\"}\n"},
+		},
+		"OTLP": {
+			path:   OTLPLogsPath,
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   otlpRequest,
+			status: http.StatusOK,
+			want: []string{
+				`{"time":"2024-05-01T10:00:00Z","severity_number":9,"severity_text":"INFO","body":"started","trace_id":"5b8efff798038103d269b633813fc60c","span_id":"eee19b7ec3c1b174","flags":1,"attributes":{"port":8080,"tags":["a",true]},"resource":{"service.name":"api"},"scope":{"name":"otelslog","version":"0.1.0"}}` + "\n",
+				`{"time":"2024-05-01T10:00:01Z","severity_number":17,"severity_text":"ERROR","body":{"code":1.5},"resource":{"service.name":"api"},"scope":{"name":"otelslog","version":"0.1.0"}}` + "\n",
+			},
+		},
+		"Gzip OTLP": {
+			path:   OTLPLogsPath,
+			header: http.Header{"Content-Type": {"application/json; charset=utf-8"}, "Content-Encoding": {"gzip"}},
+			body:   `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"hi"}}]}]}]}`,
+			gzip:   true,
+			status: http.StatusOK,
+			want:   []string{`{"body":"hi"}` + "\n"},
+		},
+		"Rejected batch": {
+			body:     "{\"msg\":\"a\"}\n",
+			statuses: []int{http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+		},
+		"OTLP protobuf": {
+			path:   OTLPLogsPath,
+			header: http.Header{"Content-Type": {"application/x-protobuf"}},
+			body:   "\x0a\x00",
+			status: http.StatusUnsupportedMediaType,
+		},
+		"Invalid OTLP": {
+			path:   OTLPLogsPath,
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"resourceLogs":{}}`,
+			status: http.StatusBadRequest,
+			err:    "invalid ExportLogsServiceRequest: json: cannot unmarshal object into Go struct field otlpLogsRequest.resourceLogs of type []struct",
+		},
+		"Invalid gzip": {
+			header: http.Header{"Content-Encoding": {"gzip"}},
+			body:   "{}",
+			status: http.StatusBadRequest,
+			err:    "invalid gzip body: unexpected EOF",
+		},
+		"Unsupported encoding": {
+			header: http.Header{"Content-Encoding": {"br"}},
+			body:   "{}",
+			status: http.StatusBadRequest,
+			err:    `unsupported content encoding "br"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recorder{}
+			wt := NewWTester(rec)
+			srv := ListenHTTP(t, wt, "collector").RespondWith(tt.statuses...)
+
+			body := []byte(tt.body)
+			if tt.gzip {
+				buf := new(bytes.Buffer)
+				zw := gzip.NewWriter(buf)
+				zw.Write(body)
+				zw.Close()
+				body = buf.Bytes()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL()+tt.path, bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("expected no error creating the request, got %v", err)
+			}
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("expected no error posting, got %v", err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, res.StatusCode)
+			}
+
+			if !slices.Equal(rec.recs, tt.want) {
+				t.Fatalf("expected records %q, got %q", tt.want, rec.recs)
+			}
+
+			err = wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok || ve.Errs[0].Title != "collector ingest" {
+				t.Fatalf("expected ingest errors, got %v", err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); !strings.HasPrefix(got, tt.err) {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestIngestServer_RespondWith(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("Delivered once", ExpectFunc(func(p []byte) bool {
+		return string(p) == "{\"msg\":\"a\"}\n"
+	})).WithMin(1).WithMax(1)
+
+	srv := ListenHTTP(t, wt, "collector").RespondWith(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusAccepted)
+
+	var statuses []int
+	for range 4 {
+		res, err := http.Post(srv.URL(), "application/x-ndjson", strings.NewReader("{\"msg\":\"a\"}\n"))
+		if err != nil {
+			t.Fatalf("expected no error posting, got %v", err)
+		}
+		res.Body.Close()
+		statuses = append(statuses, res.StatusCode)
+
+		if res.StatusCode == http.StatusAccepted {
+			break
+		}
+	}
+
+	want := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusAccepted}
+	if !slices.Equal(statuses, want) {
+		t.Fatalf("expected statuses %v, got %v", want, statuses)
+	}
+
+	if srv.Requests() != 3 {
+		t.Fatalf("expected 3 requests, got %d", srv.Requests())
+	}
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestIngestServer_RejectedRequestsKeepTheStatuses(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	srv := ListenHTTP(t, wt, "collector").RespondWith(http.StatusServiceUnavailable, http.StatusOK)
+
+	post := func(contentType, body string) int {
+		res, err := http.Post(srv.URL()+OTLPLogsPath, contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("expected no error posting, got %v", err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	res, err := http.Get(srv.URL() + OTLPLogsPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	res.Body.Close()
+
+	statuses := []int{
+		res.StatusCode,
+		post("text/plain", otlpRequest),
+		post("application/json", "{"),
+		post("application/json", otlpRequest),
+		post("application/json", otlpRequest),
+	}
+
+	want := []int{
+		http.StatusMethodNotAllowed,
+		http.StatusUnsupportedMediaType,
+		http.StatusBadRequest,
+		http.StatusServiceUnavailable,
+		http.StatusOK,
+	}
+	if !slices.Equal(statuses, want) {
+		t.Fatalf("expected statuses %v, got %v", want, statuses)
+	}
+
+	if srv.Requests() != 5 {
+		t.Fatalf("expected 5 requests, got %d", srv.Requests())
+	}
+}
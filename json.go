@@ -0,0 +1,60 @@
+package wtester
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// walkJSON calls fn for every value of a decoded JSON document,
+// in key order, with the path leading to it, e.g. "user.emails[0]".
+// The root has an empty path.
+func walkJSON(v any, path string, fn func(path string, v any)) {
+	fn(path, v)
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			walkJSON(v[k], joinPath(path, k), fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkJSON(item, path+"["+strconv.Itoa(i)+"]", fn)
+		}
+	}
+}
+
+// joinPath appends a key to a JSON path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// lookupJSON returns the value at a dotted path of a decoded JSON
+// object. A key holding the dots literally, e.g. "user.email" as
+// written by some loggers, takes precedence over nested objects.
+func lookupJSON(m map[string]any, path string) (any, bool) {
+	if v, ok := m[path]; ok {
+		return v, true
+	}
+
+	for i := strings.Index(path, "."); i >= 0; {
+		if inner, ok := m[path[:i]].(map[string]any); ok {
+			if v, ok := lookupJSON(inner, path[i+1:]); ok {
+				return v, true
+			}
+		}
+
+		next := strings.Index(path[i+1:], ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return nil, false
+}
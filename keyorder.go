@@ -0,0 +1,132 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// KeyOrderScanner is an Expecter checking the layout of JSON records:
+// the order of their keys and their formatting. Create it with
+// [KeyOrder].
+type KeyOrderScanner struct {
+	leading  []string
+	optional []string
+	sorted   bool
+	compact  bool
+}
+
+// KeyOrder returns an Expecter matching the JSON records whose
+// top-level keys start with the given keys, in order, e.g. "time",
+// "level", "msg". Every leading key is required, unless made optional
+// with [KeyOrderScanner.Optional]. Records that are not JSON objects
+// do not match. Failures report the actual order of the keys.
+func KeyOrder(leading ...string) *KeyOrderScanner {
+	return &KeyOrderScanner{
+		leading: leading,
+	}
+}
+
+// Optional makes leading keys optional: when present, they must still
+// be at their position, e.g. "error" right after "msg".
+func (s *KeyOrderScanner) Optional(keys ...string) *KeyOrderScanner {
+	s.optional = append(s.optional, keys...)
+	return s
+}
+
+// SortedTail requires the keys after the leading ones to be
+// sorted, as written by loggers sorting their attributes.
+func (s *KeyOrderScanner) SortedTail() *KeyOrderScanner {
+	s.sorted = true
+	return s
+}
+
+// Compact rejects the records with whitespace between their tokens,
+// e.g. pretty-printed ones, but for a trailing newline.
+func (s *KeyOrderScanner) Compact() *KeyOrderScanner {
+	s.compact = true
+	return s
+}
+
+func (s *KeyOrderScanner) Expect(actual []byte) bool {
+	return len(s.violations(actual)) == 0
+}
+
+// Explain returns an error describing every layout rule the record
+// breaks, along with the actual order of its keys.
+func (s *KeyOrderScanner) Explain(actual []byte) error {
+	return errors.Join(s.violations(actual)...)
+}
+
+func (s *KeyOrderScanner) violations(p []byte) []error {
+	keys, err := topLevelKeys(p)
+	if err != nil {
+		return []error{fmt.Errorf("failed to unmarshal JSON: %s", err.Error())}
+	}
+
+	var errs []error
+
+	// The leading keys expected in this record.
+	var want []string
+	for _, k := range s.leading {
+		switch {
+		case slices.Contains(keys, k):
+			want = append(want, k)
+		case !slices.Contains(s.optional, k):
+			errs = append(errs, fmt.Errorf("missing key %q", k))
+		}
+	}
+
+	if len(keys) < len(want) || !slices.Equal(keys[:len(want)], want) {
+		errs = append(errs, fmt.Errorf("expected keys to start with %q, got %q", want, keys))
+	} else if tail := keys[len(want):]; s.sorted && !slices.IsSorted(tail) {
+		errs = append(errs, fmt.Errorf("expected keys after %q to be sorted, got %q", want, keys))
+	}
+
+	if s.compact {
+		record := bytes.TrimSuffix(p, []byte("\n"))
+
+		compacted := new(bytes.Buffer)
+		if err := json.Compact(compacted, record); err == nil && !bytes.Equal(compacted.Bytes(), record) {
+			errs = append(errs, errors.New("expected compact JSON without whitespace"))
+		}
+	}
+
+	return errs
+}
+
+// topLevelKeys returns the keys of a JSON object in document order.
+func topLevelKeys(p []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,93 @@
+package wtester
+
+import (
+	"io"
+	"testing"
+)
+
+func TestKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		exp    *KeyOrderScanner
+		record string
+		err    string
+	}{
+		"Leading keys": {
+			exp:    KeyOrder("time", "level", "msg"),
+			record: `{"time":"2024-05-01T10:00:00Z","level":"INFO","msg":"started","port":8080}` + "\n",
+		},
+		"Optional key absent": {
+			exp:    KeyOrder("time", "level", "msg", "error").Optional("error").SortedTail(),
+			record: `{"time":"2024-05-01T10:00:00Z","level":"INFO","msg":"started","a":1,"b":{"z":1,"a":2}}`,
+		},
+		"Optional key present": {
+			exp:    KeyOrder("level", "msg", "error").Optional("error"),
+			record: `{"level":"ERROR","msg":"failed","error":"EOF","attempt":1}`,
+		},
+		"Wrong order": {
+			exp:    KeyOrder("time", "level", "msg"),
+			record: `{"level":"INFO","time":"2024-05-01T10:00:00Z","msg":"started"}`,
+			err:    `expected keys to start with ["time" "level" "msg"], got ["level" "time" "msg"]`,
+		},
+		"Optional key misplaced": {
+			exp:    KeyOrder("level", "msg", "error").Optional("error"),
+			record: `{"level":"ERROR","msg":"failed","attempt":1,"error":"EOF"}`,
+			err:    `expected keys to start with ["level" "msg" "error"], got ["level" "msg" "attempt" "error"]`,
+		},
+		"Missing key": {
+			exp:    KeyOrder("time", "level", "msg"),
+			record: `{"level":"INFO","msg":"started"}`,
+			err:    "missing key \"time\"",
+		},
+		"Unsorted tail": {
+			exp:    KeyOrder("msg").SortedTail(),
+			record: `{"msg":"started","port":8080,"host":"a"}`,
+			err:    `expected keys after ["msg"] to be sorted, got ["msg" "port" "host"]`,
+		},
+		"Pretty-printed": {
+			exp:    KeyOrder("msg").Compact(),
+			record: "{\n  \"msg\": \"started\"\n}\n",
+			err:    "expected compact JSON without whitespace",
+		},
+		"Whitespace in strings": {
+			exp:    KeyOrder("msg").Compact(),
+			record: `{"msg":"a b","n":1}` + "\n",
+		},
+		"Not an object": {
+			exp:    KeyOrder("msg"),
+			record: `["msg"]`,
+			err:    "failed to unmarshal JSON: expected a JSON object",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, tt.exp).Every()
+
+			if _, err := wt.Write([]byte(tt.record)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package wtester
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Framing is how a [Listener] splits the bytes it receives into records.
+type Framing int
+
+const (
+	// FrameLines makes every line a record.
+	FrameLines Framing = iota
+	// FrameSyslog frames syslog messages as in RFC 6587: a frame
+	// starting with a digit is octet counted, "LEN SP MSG", and
+	// anything else is a line. Every UDP datagram is a message, as
+	// in RFC 5426.
+	FrameSyslog
+)
+
+// defaultDrainTimeout is how long a closing Listener keeps reading
+// the data already sent, see [WithDrainTimeout].
+const defaultDrainTimeout = 100 * time.Millisecond
+
+// defaultMaxFrameSize is the largest octet counted frame accepted,
+// see [WithMaxFrameSize].
+const defaultMaxFrameSize = 64 * 1024
+
+// ListenOption configures a [Listener].
+type ListenOption func(*Listener)
+
+// WithFraming sets how the received bytes are split into records,
+// [FrameLines] by default.
+func WithFraming(f Framing) ListenOption {
+	return func(l *Listener) {
+		l.framing = f
+	}
+}
+
+// WithDrainTimeout sets how long [Listener.Close] keeps reading the
+// data still in flight before closing the connections, 100ms by default.
+func WithDrainTimeout(d time.Duration) ListenOption {
+	return func(l *Listener) {
+		l.drain = d
+	}
+}
+
+// WithMaxFrameSize sets the largest octet counted frame accepted with
+// [FrameSyslog], 64 KiB by default. A connection sending a larger
+// frame is closed and the error is returned by [Listener.Close].
+func WithMaxFrameSize(n int) ListenOption {
+	return func(l *Listener) {
+		l.maxFrame = n
+	}
+}
+
+// Listener receives records over the network and feeds them to a
+// WTester. Every connection, or every remote address for UDP, is fed
+// as its own stream named after the listener and numbered in order of
+// arrival, e.g. "syslog#1", "syslog#2", see [Listener.Streams].
+//
+// Close the listener before validating the WTester, so every record
+// sent is checked. It is closed on the test cleanup otherwise.
+type Listener struct {
+	wt       *WTester
+	name     string
+	framing  Framing
+	drain    time.Duration
+	maxFrame int
+
+	ln net.Listener
+	pc net.PacketConn
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex // guards the fields below
+	conns   map[net.Conn]struct{}
+	streams []string
+	peers   map[string]io.Writer
+	// deadline is the end of the drain once closed.
+	deadline time.Time
+	closed   bool
+	err      error
+}
+
+// ListenTCP returns a Listener accepting TCP connections on a random
+// port of the loopback interface.
+func ListenTCP(t testing.TB, wt *WTester, name string, opts ...ListenOption) *Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("wtester: listen tcp: %v", err)
+	}
+
+	return newListener(t, wt, name, ln, nil, opts)
+}
+
+// ListenUnix returns a Listener accepting connections on a Unix
+// socket created in a temporary directory removed on the test cleanup.
+func ListenUnix(t testing.TB, wt *WTester, name string, opts ...ListenOption) *Listener {
+	t.Helper()
+
+	// t.TempDir paths can exceed the maximum length of a socket path.
+	dir, err := os.MkdirTemp("", "wtester")
+	if err != nil {
+		t.Fatalf("wtester: listen unix: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	ln, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	if err != nil {
+		t.Fatalf("wtester: listen unix: %v", err)
+	}
+
+	return newListener(t, wt, name, ln, nil, opts)
+}
+
+// ListenUDP returns a Listener receiving datagrams on a random port
+// of the loopback interface.
+func ListenUDP(t testing.TB, wt *WTester, name string, opts ...ListenOption) *Listener {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("wtester: listen udp: %v", err)
+	}
+
+	return newListener(t, wt, name, nil, pc, opts)
+}
+
+func newListener(t testing.TB, wt *WTester, name string, ln net.Listener, pc net.PacketConn, opts []ListenOption) *Listener {
+	l := &Listener{
+		wt:       wt,
+		name:     name,
+		drain:    defaultDrainTimeout,
+		maxFrame: defaultMaxFrameSize,
+		ln:       ln,
+		pc:       pc,
+		conns:    make(map[net.Conn]struct{}),
+		peers:    make(map[string]io.Writer),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.wg.Add(1)
+	if ln != nil {
+		go l.accept()
+	} else {
+		go l.receive()
+	}
+
+	t.Cleanup(func() {
+		l.mu.Lock()
+		closed := l.closed
+		l.mu.Unlock()
+
+		// The errors of an explicit Close were returned to the test.
+		if closed {
+			return
+		}
+
+		if err := l.Close(); err != nil {
+			t.Errorf("wtester: closing listener %q: %v", name, err)
+		}
+	})
+
+	return l
+}
+
+// Addr returns the address the listener is bound to, to configure
+// the component under test with.
+func (l *Listener) Addr() net.Addr {
+	if l.ln != nil {
+		return l.ln.Addr()
+	}
+
+	return l.pc.LocalAddr()
+}
+
+// Streams returns the names of the streams fed so far,
+// one per connection or remote address.
+func (l *Listener) Streams() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.streams...)
+}
+
+// Close stops accepting connections, reads what was already sent for
+// up to the drain timeout, see [WithDrainTimeout], closes the
+// connections and waits for every record received to be checked.
+// It returns the first error reading the connections, if any.
+func (l *Listener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return l.err
+	}
+	l.closed = true
+	l.deadline = time.Now().Add(l.drain)
+
+	// Connections still in the accept queue are accepted and drained too.
+	if l.ln != nil {
+		l.ln.(interface{ SetDeadline(time.Time) error }).SetDeadline(l.deadline)
+	} else {
+		l.pc.SetReadDeadline(l.deadline)
+	}
+
+	for c := range l.conns {
+		c.SetReadDeadline(l.deadline)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+
+	if l.ln != nil {
+		l.ln.Close()
+	} else {
+		l.pc.Close()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// stream returns the writer of a new stream.
+func (l *Listener) stream() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	name := l.name + "#" + strconv.Itoa(len(l.streams)+1)
+	l.streams = append(l.streams, name)
+	return l.wt.Stream(name)
+}
+
+// fail records the first unexpected error.
+func (l *Listener) fail(err error) {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *Listener) accept() {
+	defer l.wg.Done()
+
+	for {
+		c, err := l.ln.Accept()
+		if err != nil {
+			l.fail(err)
+			return
+		}
+
+		l.mu.Lock()
+		if l.closed {
+			c.SetReadDeadline(l.deadline)
+		}
+		l.conns[c] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+
+		go l.serve(c, l.stream())
+	}
+}
+
+// serve feeds the records read from the connection to w until
+// it is closed by the peer or the listener.
+func (l *Listener) serve(c net.Conn, w io.Writer) {
+	defer l.wg.Done()
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, c)
+		l.mu.Unlock()
+		c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	for {
+		rec, err := l.readFrame(r)
+		if len(rec) > 0 {
+			w.Write(rec)
+		}
+
+		if err != nil {
+			l.fail(err)
+			return
+		}
+	}
+}
+
+// readFrame reads the next record, see [Framing].
+func (l *Listener) readFrame(r *bufio.Reader) ([]byte, error) {
+	if l.framing == FrameSyslog {
+		if b, err := r.Peek(1); err == nil && b[0] >= '1' && b[0] <= '9' {
+			n, err := l.readOctetCount(r)
+			if err != nil {
+				return nil, err
+			}
+
+			msg := make([]byte, n)
+			read, err := io.ReadFull(r, msg)
+			return msg[:read], err
+		}
+	}
+
+	return r.ReadBytes('\n')
+}
+
+// readOctetCount reads the "LEN SP" of an octet counted frame,
+// rejecting lengths over the maximum frame size.
+func (l *Listener) readOctetCount(r *bufio.Reader) (int, error) {
+	maxDigits := len(strconv.Itoa(l.maxFrame))
+
+	var digits []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		if c == ' ' {
+			break
+		}
+
+		digits = append(digits, c)
+		if c < '0' || c > '9' || len(digits) > maxDigits {
+			return 0, fmt.Errorf("invalid octet count %q", digits)
+		}
+	}
+
+	n, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return 0, fmt.Errorf("invalid octet count %q", digits)
+	}
+
+	if n > l.maxFrame {
+		return 0, fmt.Errorf("octet count %d exceeds the maximum frame size of %d", n, l.maxFrame)
+	}
+
+	return n, nil
+}
+
+func (l *Listener) receive() {
+	defer l.wg.Done()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := l.pc.ReadFrom(buf)
+		if n > 0 {
+			l.datagram(addr, bytes.Clone(buf[:n]))
+		}
+
+		if err != nil {
+			l.fail(err)
+			return
+		}
+	}
+}
+
+// datagram feeds the records of a datagram to the stream of its sender.
+func (l *Listener) datagram(addr net.Addr, p []byte) {
+	l.mu.Lock()
+	w, ok := l.peers[addr.String()]
+	l.mu.Unlock()
+
+	if !ok {
+		w = l.stream()
+
+		l.mu.Lock()
+		l.peers[addr.String()] = w
+		l.mu.Unlock()
+	}
+
+	if l.framing == FrameSyslog {
+		w.Write(p)
+		return
+	}
+
+	lw := &lineWriter{w: w}
+	lw.Write(p)
+	lw.Flush()
+}
@@ -0,0 +1,159 @@
+package wtester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recorder is a concurrency safe writer of the records it received.
+type recorder struct {
+	mu   sync.Mutex
+	recs []string
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recs = append(r.recs, string(p))
+	return len(p), nil
+}
+
+func TestListen(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		listen  func(t testing.TB, wt *WTester, name string, opts ...ListenOption) *Listener
+		framing Framing
+		conns   []string
+		want    []string
+	}{
+		"TCP lines": {
+			listen: ListenTCP,
+			conns:  []string{"first\nsec", "third\n"},
+			want:   []string{"first\n", "sec", "third\n"},
+		},
+		"TCP syslog": {
+			listen:  ListenTCP,
+			framing: FrameSyslog,
+			conns:   []string{"11 <13>1 a\nb c17 <13>1 - - - - - -<13>1 d\n"},
+			want:    []string{"<13>1 a\nb c", "<13>1 - - - - - -", "<13>1 d\n"},
+		},
+		"Unix lines": {
+			listen: ListenUnix,
+			conns:  []string{"first\nsecond\n"},
+			want:   []string{"first\n", "second\n"},
+		},
+		"UDP lines": {
+			listen: ListenUDP,
+			conns:  []string{"first\nsecond", "third\n"},
+			want:   []string{"first\n", "second", "third\n"},
+		},
+		"UDP syslog": {
+			listen:  ListenUDP,
+			framing: FrameSyslog,
+			conns:   []string{"<13>1 a\nb", "<13>1 c\n"},
+			want:    []string{"<13>1 a\nb", "<13>1 c\n"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := &recorder{}
+			wt := NewWTester(rec)
+			wt.Expect("Has a space", ExpectFunc(func(p []byte) bool {
+				return bytes.Contains(p, []byte(" "))
+			})).OnStreams("logs#1")
+
+			ln := tt.listen(t, wt, "logs", WithFraming(tt.framing))
+
+			for _, payload := range tt.conns {
+				c, err := net.Dial(ln.Addr().Network(), ln.Addr().String())
+				if err != nil {
+					t.Fatalf("expected no error dialing, got %v", err)
+				}
+
+				if _, err := c.Write([]byte(payload)); err != nil {
+					t.Fatalf("expected no error writing, got %v", err)
+				}
+				c.Close()
+			}
+
+			if err := ln.Close(); err != nil {
+				t.Fatalf("expected no error closing, got %v", err)
+			}
+
+			got := slices.Clone(rec.recs)
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Fatalf("expected records %q, got %q", want, got)
+			}
+
+			var streams []string
+			for i := range tt.conns {
+				streams = append(streams, fmt.Sprintf("logs#%d", i+1))
+			}
+			if got := ln.Streams(); !slices.Equal(got, streams) {
+				t.Fatalf("expected streams %q, got %q", streams, got)
+			}
+
+			hasSpace := slices.ContainsFunc(tt.want, func(s string) bool {
+				return bytes.Contains([]byte(s), []byte(" "))
+			})
+			if err := wt.Validate(); (err == nil) != hasSpace {
+				t.Fatalf("expected records with a space %v, got %v", hasSpace, err)
+			}
+		})
+	}
+}
+
+func TestListen_OversizedFrames(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		payload string
+		err     string
+	}{
+		"Huge octet count": {
+			payload: "99999999999999 x",
+			err:     `invalid octet count "99999"`,
+		},
+		"Octet count over the maximum": {
+			payload: "2048 x",
+			err:     "octet count 2048 exceeds the maximum frame size of 1024",
+		},
+		"Digits without a space": {
+			payload: "12345678901234567890",
+			err:     `invalid octet count "12345"`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			ln := ListenTCP(t, wt, "logs", WithFraming(FrameSyslog), WithMaxFrameSize(1024))
+
+			c, err := net.Dial(ln.Addr().Network(), ln.Addr().String())
+			if err != nil {
+				t.Fatalf("expected no error dialing, got %v", err)
+			}
+			c.Write([]byte(tt.payload))
+			c.Close()
+
+			if err := ln.Close(); err == nil || err.Error() != tt.err {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// logfmtPair is a key=value pair of a logfmt record.
+type logfmtPair struct {
+	key   string
+	value string
+}
+
+// parseLogfmt parses a record made of space separated key=value pairs,
+// as written by slog.TextHandler, keeping the order of the keys.
+// Values may be quoted with Go syntax. A bare key is a key with an
+// empty value.
+func parseLogfmt(s string) ([]logfmtPair, error) {
+	var pairs []logfmtPair
+
+	s = strings.TrimRight(s, "\r\n")
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+
+		end := strings.IndexAny(s[i:], "= ")
+		if end < 0 {
+			end = len(s) - i
+		}
+
+		key := s[i : i+end]
+		if key == "" || strings.ContainsAny(key, `"`) {
+			return nil, fmt.Errorf("invalid logfmt key at offset %d", i)
+		}
+
+		i += end
+		if i >= len(s) || s[i] == ' ' {
+			pairs = append(pairs, logfmtPair{key: key})
+			continue
+		}
+
+		// Skip the '='.
+		i++
+		if i < len(s) && s[i] == '"' {
+			quoted, err := strconv.QuotedPrefix(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid logfmt value of %q: %w", key, err)
+			}
+
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid logfmt value of %q: %w", key, err)
+			}
+
+			pairs = append(pairs, logfmtPair{key: key, value: value})
+			i += len(quoted)
+			continue
+		}
+
+		end = strings.IndexByte(s[i:], ' ')
+		if end < 0 {
+			end = len(s) - i
+		}
+
+		pairs = append(pairs, logfmtPair{key: key, value: s[i : i+end]})
+		i += end
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("empty logfmt record")
+	}
+
+	return pairs, nil
+}
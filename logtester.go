@@ -1,10 +1,10 @@
 package wtester
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"sync"
+	"time"
 )
 
 // WTester is a wrapper around an [io.Writer] that allows
@@ -17,13 +17,26 @@ type WTester struct {
 	expects map[string]*Expect
 	errors  map[string]*ExpectError
 	muErr   sync.Mutex
+
+	callers     bool
+	callerSkips []string
+
+	secrets *SecretGuard
+	crashes *crashDetector
+	grouper *Grouper
+
+	containers *containerUnwrapper
+
+	decoders map[string]Decoding
+	muDec    sync.RWMutex // guards decoders
 }
 
 func NewWTester(w io.Writer) *WTester {
 	return &WTester{
-		w:       w,
-		expects: make(map[string]*Expect),
-		errors:  make(map[string]*ExpectError),
+		w:        w,
+		expects:  make(map[string]*Expect),
+		errors:   make(map[string]*ExpectError),
+		decoders: make(map[string]Decoding),
 	}
 }
 
@@ -46,26 +59,129 @@ func (l *WTester) AppendWriter(w io.Writer) {
 // [io.Writer] and checks if the byte slice matches any of
 // the expectations set on the WTester.
 func (l *WTester) Write(p []byte) (n int, err error) {
-	// Only unmarshal JSON once. And only if there are JSON expectations.
-	var m map[string]any
+	return l.write("", p)
+}
+
+// write checks p against the expectations that apply to the
+// given stream and writes it to the underlying [io.Writer].
+// The unnamed stream "" is the one used by Write.
+func (l *WTester) write(stream string, p []byte) (n int, err error) {
+	rec := &rawRecord{
+		stream: stream,
+		p:      p,
+		pcs:    l.captureCallers(),
+	}
+
+	// Redact the leaked secrets before anything else sees the bytes.
+	if l.secrets != nil {
+		var leak error
+		if rec.p, leak = l.secrets.check(p); leak != nil {
+			l.appendError(SecretsTitle, l.errorRecord(rec, leak))
+		}
+	}
+
+	if l.containers != nil {
+		for _, inner := range l.containers.unwrap(rec) {
+			l.process(inner)
+		}
+	} else {
+		l.process(rec)
+	}
+
+	l.muW.Lock()
+	defer l.muW.Unlock()
+
+	n, err = l.w.Write(rec.p)
+	if err == nil && n == len(rec.p) {
+		// Redacting changes the length, report the caller's.
+		n = len(p)
+	}
 
+	return n, err
+}
+
+// process runs the crash detection and the expectations on a record.
+func (l *WTester) process(rec *rawRecord) {
+	if l.crashes != nil {
+		l.crashes.feed(rec.stream, rec.p)
+	}
+
+	if l.grouper != nil {
+		l.grouper.feed(rec)
+	} else {
+		l.check(rec)
+	}
+}
+
+// rawRecord is a unit of output checked against the expectations.
+type rawRecord struct {
+	stream string
+	p      []byte
+	// runtimeStream and time are the stream and the timestamp
+	// of the record set by its container runtime, if any.
+	runtimeStream string
+	time          time.Time
+	// pcs is the stack that wrote the record, resolved
+	// to caller only if the record fails.
+	pcs    []uintptr
+	caller string
+	// decoded holds the representations decoded so far, by decoder name.
+	decoded map[string]decoded
+}
+
+// recordCaller returns the caller of the record, resolving it once.
+func (l *WTester) recordCaller(rec *rawRecord) string {
+	if rec.caller == "" {
+		rec.caller = l.callerOf(rec.pcs)
+	}
+
+	return rec.caller
+}
+
+// errorRecord returns the ErrorRecord reporting the record.
+func (l *WTester) errorRecord(rec *rawRecord, err error) ErrorRecord {
+	return ErrorRecord{
+		Bytes:         rec.p,
+		Err:           err,
+		Caller:        l.recordCaller(rec),
+		Stream:        rec.stream,
+		RuntimeStream: rec.runtimeStream,
+		Time:          rec.time,
+	}
+}
+
+// check checks the record against the expectations that apply
+// to its stream.
+func (l *WTester) check(rec *rawRecord) {
 	for _, e := range l.expects {
-		var ok bool
-		switch exp := e.exp.(type) {
-		case JSONExpecter:
-			if m == nil {
-				if err := json.Unmarshal(p, &m); err != nil {
-					l.appendError(e.title, ErrorRecord{
-						Bytes: p,
-						Err:   fmt.Errorf("failed to unmarshal JSON: %s", err.Error()),
-					})
-					continue
-				}
+		if !e.onStream(rec.stream) {
+			continue
+		}
+
+		dec, err := l.decoderOf(e.exp)
+		if err != nil {
+			l.appendError(e.title, l.errorRecord(rec, err))
+			continue
+		}
+
+		var (
+			ok bool
+			v  any
+		)
+
+		if dec != nil {
+			res := l.decodeRecord(rec, dec)
+			if res.err != nil {
+				continue
 			}
 
-			ok = exp.ExpectJSON(m)
-		default:
-			ok = exp.Expect(p)
+			v = res.v
+			if ok, err = expectDecoded(e.exp, dec, v); err != nil {
+				l.appendError(e.title, l.errorRecord(rec, err))
+				continue
+			}
+		} else {
+			ok = e.exp.Expect(rec.p)
 		}
 
 		if ok {
@@ -74,16 +190,48 @@ func (l *WTester) Write(p []byte) (n int, err error) {
 		}
 
 		if !ok && e.every {
-			l.appendError(e.title, ErrorRecord{
-				Bytes: p,
-			})
+			er := l.errorRecord(rec, nil)
+
+			if dec != nil {
+				er.Err = explainDecoded(e.exp, dec, v)
+			} else if ex, isExplainer := e.exp.(Explainer); isExplainer {
+				er.Err = ex.Explain(rec.p)
+			}
+
+			l.appendError(e.title, er)
 		}
 	}
+}
 
-	l.muW.Lock()
-	defer l.muW.Unlock()
+// expectDecoded checks a decoded record against the expectation,
+// failing if the expectation does not accept its type.
+func expectDecoded(exp Expecter, dec Decoding, v any) (bool, error) {
+	if jexp, isJSON := exp.(JSONExpecter); isJSON {
+		m, isMap := v.(map[string]any)
+		if !isMap {
+			return false, fmt.Errorf("decoder %q produced %T, the expectation needs map[string]any", dec.Name(), v)
+		}
+
+		return jexp.ExpectJSON(m), nil
+	}
 
-	return l.w.Write(p)
+	ok, handled := dec.expectAny(exp, v)
+	if !handled {
+		return false, fmt.Errorf("decoder %q produced %T, the expectation does not accept it", dec.Name(), v)
+	}
+
+	return ok, nil
+}
+
+// explainDecoded explains why a decoded record failed the
+// expectation, if it can tell.
+func explainDecoded(exp Expecter, dec Decoding, v any) error {
+	if jex, isJSON := exp.(JSONExplainer); isJSON {
+		return jex.ExplainJSON(v.(map[string]any))
+	}
+
+	err, _ := dec.explainAny(exp, v)
+	return err
 }
 
 // Close closes the underlying io.Writer if it implements
@@ -114,8 +262,10 @@ func (l *WTester) ExpectFunc(title string, f func(actual []byte) bool) *Expect {
 	return l.Expect(title, ExpectFunc(f))
 }
 
-// Reset resets the WTester by clearing all expectations
-// and errors.
+// Reset resets the WTester by clearing all expectations,
+// forbidden secrets, crash detection and errors. The records
+// still being grouped, see [WTester.GroupRecords], and the
+// partial container lines are dropped.
 func (l *WTester) Reset() {
 	l.muW.Lock()
 	defer l.muW.Unlock()
@@ -125,6 +275,16 @@ func (l *WTester) Reset() {
 
 	l.expects = make(map[string]*Expect)
 	l.errors = make(map[string]*ExpectError)
+	l.secrets = nil
+	l.crashes = nil
+
+	if l.containers != nil {
+		l.containers.flush()
+	}
+
+	if l.grouper != nil {
+		l.grouper.reset()
+	}
 }
 
 // Validate validates the expectations set on the WTester
@@ -133,6 +293,20 @@ func (l *WTester) Reset() {
 // You must cast the err ve, ok := err.(*ValidationErrors) to access
 // the underlying validation errors.
 func (l *WTester) Validate() error {
+	if l.containers != nil {
+		for _, inner := range l.containers.flush() {
+			l.process(inner)
+		}
+	}
+
+	if l.crashes != nil {
+		l.crashes.flush()
+	}
+
+	if l.grouper != nil {
+		l.grouper.flush()
+	}
+
 	for _, e := range l.expects {
 		switch {
 		case e.min > 0 && e.matches < e.min:
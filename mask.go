@@ -0,0 +1,239 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// HashFormat is the alphabet of a hashed value, see [MaskingPolicy.Hashed].
+type HashFormat string
+
+const (
+	HashHex       HashFormat = "hex"
+	HashBase64    HashFormat = "base64"
+	HashBase64URL HashFormat = "base64url"
+)
+
+// chars returns the characters a value of the format may contain.
+func (f HashFormat) chars() string {
+	const alnum = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+	switch f {
+	case HashHex:
+		return "0123456789abcdefABCDEF"
+	case HashBase64:
+		return alnum + "+/="
+	case HashBase64URL:
+		return alnum + "-_="
+	}
+
+	return ""
+}
+
+type maskKind int
+
+const (
+	maskFull maskKind = iota
+	maskKeepLast
+	maskKeepFirst
+	maskEmailDomain
+	maskHashed
+	maskAbsent
+)
+
+type maskRule struct {
+	field  string
+	kind   maskKind
+	n      int
+	format HashFormat
+}
+
+// MaskingPolicy is a [JSONExpecter] checking how sensitive fields are
+// masked. Create it with [MaskPolicy].
+type MaskingPolicy struct {
+	maskChar   rune
+	separators string
+	rules      []maskRule
+}
+
+// MaskPolicy returns an Expecter matching the JSON records where every
+// field with a rule complies with it. Fields are looked up by their
+// dotted path, e.g. "payment.card". A field missing from the record
+// complies with every rule, use it with [Expect.Every].
+//
+// Lengths are counted in characters, not bytes, and the separators,
+// spaces and dashes by default, are neither masked nor visible
+// characters, so "****-****-****-1234" keeps the last 4 characters.
+// The mask character defaults to '*'.
+func MaskPolicy() *MaskingPolicy {
+	return &MaskingPolicy{
+		maskChar:   '*',
+		separators: " -",
+	}
+}
+
+// WithMaskChar sets the character masked values are made of.
+func (p *MaskingPolicy) WithMaskChar(r rune) *MaskingPolicy {
+	p.maskChar = r
+	return p
+}
+
+// WithSeparators sets the characters ignored when counting masked
+// and visible characters.
+func (p *MaskingPolicy) WithSeparators(separators string) *MaskingPolicy {
+	p.separators = separators
+	return p
+}
+
+// FullMask requires the fields to be entirely masked.
+func (p *MaskingPolicy) FullMask(fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskFull}, fields)
+}
+
+// KeepLast requires the fields to be masked except, at most, their
+// last n characters, e.g. a card showing only its last 4 digits.
+func (p *MaskingPolicy) KeepLast(n int, fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskKeepLast, n: n}, fields)
+}
+
+// KeepFirst requires the fields to be masked except, at most,
+// their first n characters.
+func (p *MaskingPolicy) KeepFirst(n int, fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskKeepFirst, n: n}, fields)
+}
+
+// KeepEmailDomain requires the fields to be emails whose local part
+// is entirely masked, e.g. "****@example.com".
+func (p *MaskingPolicy) KeepEmailDomain(fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskEmailDomain}, fields)
+}
+
+// Hashed requires the fields to be replaced by a hash in the given
+// format. With a length greater than 0, the hash must also be exactly
+// that many characters long.
+func (p *MaskingPolicy) Hashed(format HashFormat, length int, fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskHashed, n: length, format: format}, fields)
+}
+
+// Absent requires the fields to not be logged at all.
+func (p *MaskingPolicy) Absent(fields ...string) *MaskingPolicy {
+	return p.add(maskRule{kind: maskAbsent}, fields)
+}
+
+func (p *MaskingPolicy) add(rule maskRule, fields []string) *MaskingPolicy {
+	for _, f := range fields {
+		rule.field = f
+		p.rules = append(p.rules, rule)
+	}
+
+	return p
+}
+
+// Only for satisfy the Expecter interface.
+func (p *MaskingPolicy) Expect(actual []byte) bool {
+	return false
+}
+
+// ExpectJSON checks that every field of the record with a rule complies.
+func (p *MaskingPolicy) ExpectJSON(m map[string]any) bool {
+	return len(p.violations(m)) == 0
+}
+
+// ExplainJSON returns an error describing every field that
+// does not comply with its rule.
+func (p *MaskingPolicy) ExplainJSON(m map[string]any) error {
+	return errors.Join(p.violations(m)...)
+}
+
+func (p *MaskingPolicy) violations(m map[string]any) []error {
+	var errs []error
+	for _, rule := range p.rules {
+		v, ok := lookupJSON(m, rule.field)
+		if !ok {
+			continue
+		}
+
+		if rule.kind == maskAbsent {
+			errs = append(errs, fmt.Errorf("field %q: expected to be absent", rule.field))
+			continue
+		}
+
+		str, ok := v.(string)
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %q: expected a string, got %T", rule.field, v))
+			continue
+		}
+
+		if err := p.check(rule, str); err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", rule.field, err))
+		}
+	}
+
+	return errs
+}
+
+// check returns an error if the value does not comply with the rule.
+func (p *MaskingPolicy) check(rule maskRule, value string) error {
+	switch rule.kind {
+	case maskFull:
+		if !p.masked(p.runes(value)) {
+			return errors.New("expected to be fully masked")
+		}
+	case maskKeepLast:
+		rs := p.runes(value)
+		if len(rs) <= rule.n || !p.masked(rs[:len(rs)-rule.n]) {
+			return fmt.Errorf("expected to be masked except the last %d characters", rule.n)
+		}
+	case maskKeepFirst:
+		rs := p.runes(value)
+		if len(rs) <= rule.n || !p.masked(rs[rule.n:]) {
+			return fmt.Errorf("expected to be masked except the first %d characters", rule.n)
+		}
+	case maskEmailDomain:
+		at := strings.LastIndex(value, "@")
+		if at < 0 || at == len(value)-1 || !p.masked(p.runes(value[:at])) {
+			return errors.New("expected an email with a masked local part")
+		}
+	case maskHashed:
+		chars := rule.format.chars()
+		if value == "" || strings.Trim(value, chars) != "" {
+			return fmt.Errorf("expected a %s hash", rule.format)
+		}
+
+		if rule.n > 0 && utf8.RuneCountInString(value) != rule.n {
+			return fmt.Errorf("expected a %s hash of %d characters", rule.format, rule.n)
+		}
+	}
+
+	return nil
+}
+
+// runes returns the characters of value, without the separators.
+func (p *MaskingPolicy) runes(value string) []rune {
+	var rs []rune
+	for _, r := range value {
+		if !strings.ContainsRune(p.separators, r) {
+			rs = append(rs, r)
+		}
+	}
+
+	return rs
+}
+
+// masked reports whether rs is made of mask characters only.
+// Nothing masked is not masked.
+func (p *MaskingPolicy) masked(rs []rune) bool {
+	if len(rs) == 0 {
+		return false
+	}
+
+	for _, r := range rs {
+		if r != p.maskChar {
+			return false
+		}
+	}
+
+	return true
+}
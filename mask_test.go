@@ -0,0 +1,130 @@
+package wtester
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func ExampleMaskPolicy() {
+	wt := NewWTester(io.Discard)
+
+	wt.Expect("Sensitive fields are masked", MaskPolicy().
+		KeepLast(4, "card").
+		KeepEmailDomain("email").
+		Hashed(HashHex, 64, "user.document").
+		Absent("password"),
+	).Every()
+
+	wt.Write([]byte(`{"card":"****-****-****-1234","email":"*****@example.com"}`))
+	wt.Write([]byte(`{"card":"1234-****-****-1234","password":"hunter2"}`))
+
+	err := wt.Validate()
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// validation "Sensitive fields are masked"
+	// Fails On:
+	// {"card":"1234-****-****-1234","password":"hunter2"}
+	// field "card": expected to be masked except the last 4 characters
+	// field "password": expected to be absent
+}
+
+func TestMaskPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		policy   *MaskingPolicy
+		input    string
+		expected string
+	}{
+		"Fully masked": {
+			policy: MaskPolicy().FullMask("password"),
+			input:  `{"password":"********"}`,
+		},
+		"Partially masked": {
+			policy:   MaskPolicy().FullMask("password"),
+			input:    `{"password":"p******d"}`,
+			expected: `field "password": expected to be fully masked`,
+		},
+		"Last four digits visible": {
+			policy: MaskPolicy().KeepLast(4, "card"),
+			input:  `{"card":"****-****-****-1234"}`,
+		},
+		"First digits visible too": {
+			policy:   MaskPolicy().KeepLast(4, "card"),
+			input:    `{"card":"12**-****-****-1234"}`,
+			expected: `field "card": expected to be masked except the last 4 characters`,
+		},
+		"Multibyte mask counted in characters": {
+			policy: MaskPolicy().WithMaskChar('•').KeepFirst(2, "name"),
+			input:  `{"name":"Jo••••"}`,
+		},
+		"Email keeps the domain": {
+			policy: MaskPolicy().KeepEmailDomain("email"),
+			input:  `{"email":"****@example.com"}`,
+		},
+		"Email local part visible": {
+			policy:   MaskPolicy().KeepEmailDomain("email"),
+			input:    `{"email":"jo**@example.com"}`,
+			expected: `field "email": expected an email with a masked local part`,
+		},
+		"Hashed nested field": {
+			policy: MaskPolicy().Hashed(HashHex, 8, "user.document"),
+			input:  `{"user":{"document":"9f86d081"}}`,
+		},
+		"Hash of the wrong length": {
+			policy:   MaskPolicy().Hashed(HashHex, 64, "user.document"),
+			input:    `{"user":{"document":"9f86d081"}}`,
+			expected: `field "user.document": expected a hex hash of 64 characters`,
+		},
+		"Absent field present": {
+			policy:   MaskPolicy().Absent("password"),
+			input:    `{"password":"********"}`,
+			expected: `field "password": expected to be absent`,
+		},
+		"All present fields must comply": {
+			policy:   MaskPolicy().FullMask("password", "token"),
+			input:    `{"password":"********","token":"abc"}`,
+			expected: `field "token": expected to be fully masked`,
+		},
+		"Missing fields comply": {
+			policy: MaskPolicy().FullMask("password").KeepLast(4, "card"),
+			input:  `{"msg":"hello"}`,
+		},
+		"Not a string": {
+			policy:   MaskPolicy().FullMask("password"),
+			input:    `{"password":1234}`,
+			expected: `field "password": expected a string, got float64`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, tt.policy).Every()
+			wt.Write([]byte(tt.input))
+
+			err := wt.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			explanation := ve.Errs[0].Errors[0].Err.Error()
+			if !strings.Contains(explanation, tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, explanation)
+			}
+		})
+	}
+}
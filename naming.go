@@ -0,0 +1,236 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyCase is a naming convention of keys, see [KeyNaming].
+type KeyCase int
+
+const (
+	SnakeCase KeyCase = iota
+	CamelCase
+	KebabCase
+)
+
+func (c KeyCase) String() string {
+	switch c {
+	case CamelCase:
+		return "camelCase"
+	case KebabCase:
+		return "kebab-case"
+	}
+
+	return "snake_case"
+}
+
+var keyCasePatterns = map[KeyCase]*regexp.Regexp{
+	SnakeCase: regexp.MustCompile(`^\p{Ll}[\p{Ll}0-9]*(_[\p{Ll}0-9]+)*$`),
+	CamelCase: regexp.MustCompile(`^\p{Ll}[\p{Ll}0-9]*(\p{Lu}[\p{Ll}0-9]*)*$`),
+	KebabCase: regexp.MustCompile(`^\p{Ll}[\p{Ll}0-9]*(-[\p{Ll}0-9]+)*$`),
+}
+
+// spell writes the words in the convention.
+func (c KeyCase) spell(words []string) string {
+	switch c {
+	case CamelCase:
+		for i := 1; i < len(words); i++ {
+			r, size := utf8.DecodeRuneInString(words[i])
+			words[i] = string(unicode.ToUpper(r)) + words[i][size:]
+		}
+		return strings.Join(words, "")
+	case KebabCase:
+		return strings.Join(words, "-")
+	}
+
+	return strings.Join(words, "_")
+}
+
+// KeyNamingScanner is an Expecter checking that the keys of the
+// records follow a naming convention. Create it with [KeyNaming]
+// or [KeyNamingPattern].
+type KeyNamingScanner struct {
+	keyCase    KeyCase
+	pattern    *regexp.Regexp
+	exceptions []string
+	reserved   []string
+}
+
+// KeyNaming returns an Expecter matching the records whose keys all
+// follow the naming convention, e.g. "user_id" in [SnakeCase]. JSON
+// records are checked at every depth, other records are parsed as
+// key=value pairs. Keys with dots, e.g. "http.status_code", are
+// namespaced and each of their segments is checked. Failures name the
+// path of every non-conforming key and suggest its conforming spelling.
+func KeyNaming(c KeyCase) *KeyNamingScanner {
+	return &KeyNamingScanner{
+		keyCase: c,
+	}
+}
+
+// KeyNamingPattern is like [KeyNaming] but with a custom convention:
+// the keys, dots included, must match the pattern. No spelling is
+// suggested. It panics if the pattern does not compile.
+func KeyNamingPattern(pattern string) *KeyNamingScanner {
+	return &KeyNamingScanner{
+		pattern: regexp.MustCompile(pattern),
+	}
+}
+
+// Except exempts keys from the convention and the reserved prefixes,
+// e.g. "@timestamp". An exception is either a key, exempted at any
+// depth, or the path of a key, e.g. "headers.X-Request-ID".
+func (s *KeyNamingScanner) Except(keys ...string) *KeyNamingScanner {
+	s.exceptions = append(s.exceptions, keys...)
+	return s
+}
+
+// Reserve reserves key prefixes, e.g. "_" or "otel.", for the logging
+// pipeline: the keys starting with them are rejected, unless they are
+// exceptions.
+func (s *KeyNamingScanner) Reserve(prefixes ...string) *KeyNamingScanner {
+	s.reserved = append(s.reserved, prefixes...)
+	return s
+}
+
+func (s *KeyNamingScanner) Expect(actual []byte) bool {
+	return len(s.violations(actual)) == 0
+}
+
+// Explain returns an error naming every key breaking the convention.
+func (s *KeyNamingScanner) Explain(actual []byte) error {
+	return errors.Join(s.violations(actual)...)
+}
+
+func (s *KeyNamingScanner) violations(p []byte) []error {
+	var errs []error
+	check := func(parent, key string) {
+		if err := s.checkKey(parent, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	trimmed := bytes.TrimSpace(p)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var m map[string]any
+		if err := json.Unmarshal(trimmed, &m); err != nil {
+			return []error{fmt.Errorf("failed to unmarshal JSON: %s", err.Error())}
+		}
+
+		walkKeys(m, "", check)
+		return errs
+	}
+
+	pairs, err := parseLogfmt(string(p))
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, pair := range pairs {
+		check("", pair.key)
+	}
+
+	return errs
+}
+
+// walkKeys calls fn for every key of the JSON value, in key order,
+// with the path of the object holding it.
+func walkKeys(v any, path string, fn func(parent, key string)) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			fn(path, k)
+			walkKeys(v[k], joinPath(path, k), fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkKeys(item, path+"["+strconv.Itoa(i)+"]", fn)
+		}
+	}
+}
+
+// checkKey checks a key held by the object at the parent path.
+func (s *KeyNamingScanner) checkKey(parent, key string) error {
+	path := joinPath(parent, key)
+	if slices.Contains(s.exceptions, key) || slices.Contains(s.exceptions, path) {
+		return nil
+	}
+
+	for _, prefix := range s.reserved {
+		if strings.HasPrefix(key, prefix) {
+			return fmt.Errorf("key %q uses the reserved prefix %q", path, prefix)
+		}
+	}
+
+	if s.pattern != nil {
+		if !s.pattern.MatchString(key) {
+			return fmt.Errorf("key %q does not match %q", path, s.pattern)
+		}
+		return nil
+	}
+
+	segments := strings.Split(key, ".")
+	conforming := true
+	for i, seg := range segments {
+		if !keyCasePatterns[s.keyCase].MatchString(seg) {
+			conforming = false
+			if words := splitWords(seg); len(words) > 0 {
+				segments[i] = s.keyCase.spell(words)
+			}
+		}
+	}
+
+	if conforming {
+		return nil
+	}
+
+	suggestion := joinPath(parent, strings.Join(segments, "."))
+	return fmt.Errorf("key %q is not %s, expected %q", path, s.keyCase, suggestion)
+}
+
+// splitWords splits a key into lowercase words, at separators
+// and case changes, e.g. "UserID" into "user" and "id".
+func splitWords(s string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r):
+			// A new word starts after a lowercase letter or a digit, or
+			// at the last uppercase letter of an acronym, e.g. "HTTPServer".
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+			word = append(word, unicode.ToLower(r))
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	return words
+}
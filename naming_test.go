@@ -0,0 +1,119 @@
+package wtester
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestKeyNaming(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		exp    *KeyNamingScanner
+		record string
+		err    string
+	}{
+		"Snake case JSON": {
+			exp:    KeyNaming(SnakeCase).Except("@timestamp"),
+			record: `{"@timestamp":"2024-05-01T10:00:00Z","msg":"a","http.status_code":200,"user":{"user_id":1},"items":[{"item_id":2}]}`,
+		},
+		"Snake case logfmt": {
+			exp:    KeyNaming(SnakeCase),
+			record: "level=INFO msg=started user_id=1 http.status_code=200\n",
+		},
+		"Camel case": {
+			exp:    KeyNaming(CamelCase),
+			record: `{"msg":"a","userId":1,"requestURL":"/"}`,
+		},
+		"Kebab case": {
+			exp:    KeyNaming(KebabCase),
+			record: "msg=a user-id=1",
+		},
+		"Custom pattern": {
+			exp:    KeyNamingPattern(`^[a-z]+$`),
+			record: `{"msg":"a","user":{"Id":1}}`,
+			err:    `key "user.Id" does not match "^[a-z]+$"`,
+		},
+		"Non conforming JSON keys": {
+			exp:    KeyNaming(SnakeCase),
+			record: `{"msg":"a","userId":1,"UserID":2,"user-id":3,"http.statusCode":200,"items":[{"HTTPServer":"a"}]}`,
+			err: `key "UserID" is not snake_case, expected "user_id"
+key "http.statusCode" is not snake_case, expected "http.status_code"
+key "items[0].HTTPServer" is not snake_case, expected "items[0].http_server"
+key "user-id" is not snake_case, expected "user_id"
+key "userId" is not snake_case, expected "user_id"`,
+		},
+		"Non conforming logfmt keys": {
+			exp:    KeyNaming(CamelCase),
+			record: "msg=a user_id=1 request-URL=/",
+			err: `key "user_id" is not camelCase, expected "userId"
+key "request-URL" is not camelCase, expected "requestUrl"`,
+		},
+		"Non ASCII key": {
+			exp:    KeyNaming(CamelCase),
+			record: `{"user_über":1,"userÜber":2}`,
+			err:    `key "user_über" is not camelCase, expected "userÜber"`,
+		},
+		"Exception by path": {
+			exp:    KeyNaming(KebabCase).Except("headers.X-Request-ID"),
+			record: `{"headers":{"X-Request-ID":"a","X-Trace":"b"}}`,
+			err:    `key "headers.X-Trace" is not kebab-case, expected "headers.x-trace"`,
+		},
+		"Reserved prefixes": {
+			exp:    KeyNaming(SnakeCase).Reserve("_", "otel.").Except("_id"),
+			record: `{"_id":"a","_source":"b","otel.scope":"c"}`,
+			err: `key "_source" uses the reserved prefix "_"
+key "otel.scope" uses the reserved prefix "otel."`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, tt.exp).Every()
+
+			if _, err := wt.Write([]byte(tt.record)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"user_id":    {"user", "id"},
+		"userID":     {"user", "id"},
+		"UserId":     {"user", "id"},
+		"HTTPServer": {"http", "server"},
+		"user--id":   {"user", "id"},
+		"v2Name":     {"v2", "name"},
+	}
+
+	for key, want := range tests {
+		if got := splitWords(key); !slices.Equal(got, want) {
+			t.Errorf("splitWords(%q): expected %q, got %q", key, want, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Titles of the expectations of [OTelPack].
+const (
+	OTelTraceIDTitle        = "otel trace_id"
+	OTelSpanIDTitle         = "otel span_id"
+	OTelSeverityTitle       = "otel severity"
+	OTelResourceTitle       = "otel resource"
+	OTelAttributeNamesTitle = "otel attribute names"
+)
+
+// otelName matches the attribute names following the semantic
+// conventions: lowercase snake_case words in dot separated namespaces.
+var otelName = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*(\.[a-z][a-z0-9]*(_[a-z0-9]+)*)*$`)
+
+// otelSeverities are the ranges of severity numbers of the short
+// severity names of the log data model.
+var otelSeverities = map[string][2]int{
+	"TRACE": {1, 4},
+	"DEBUG": {5, 8},
+	"INFO":  {9, 12},
+	"WARN":  {13, 16},
+	"ERROR": {17, 20},
+	"FATAL": {21, 24},
+}
+
+// OTelPack returns a [Pack] checking that the records follow the
+// OpenTelemetry log data model, as flattened by [IngestServer]:
+//
+//   - "trace_id", if any, is 32 lowercase hex characters, not all zeros;
+//   - "span_id", if any, is 16 lowercase hex characters, not all zeros;
+//   - "severity_number", if any, is 1 to 24 and in the range of
+//     "severity_text" when it is a short name, e.g. 9 to 12 for INFO;
+//   - the "resource" holds the given attributes, "service.name" if none;
+//   - the names of the "attributes" and "resource" attributes follow the
+//     semantic conventions, e.g. "http.response.status_code".
+func OTelPack(resourceAttrs ...string) Pack {
+	if len(resourceAttrs) == 0 {
+		resourceAttrs = []string{"service.name"}
+	}
+
+	return Pack{
+		OTelTraceIDTitle:        jsonRule(func(m map[string]any) error { return checkOTelID(m, "trace_id", 32) }),
+		OTelSpanIDTitle:         jsonRule(func(m map[string]any) error { return checkOTelID(m, "span_id", 16) }),
+		OTelSeverityTitle:       jsonRule(checkOTelSeverity),
+		OTelResourceTitle:       jsonRule(func(m map[string]any) error { return checkOTelResource(m, resourceAttrs) }),
+		OTelAttributeNamesTitle: jsonRule(checkOTelNames),
+	}
+}
+
+// checkOTelID checks the hex ID of the key, if any.
+func checkOTelID(m map[string]any, key string, length int) error {
+	v, ok := m[key]
+	if !ok {
+		return nil
+	}
+
+	id, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a string, got %v", key, v)
+	}
+
+	if len(id) != length || strings.Trim(id, "0123456789abcdef") != "" {
+		return fmt.Errorf("%s %q must be %d lowercase hex characters", key, id, length)
+	}
+
+	if strings.Trim(id, "0") == "" {
+		return fmt.Errorf("%s %q must not be all zeros", key, id)
+	}
+
+	return nil
+}
+
+func checkOTelSeverity(m map[string]any) error {
+	v, ok := m["severity_number"]
+	if !ok {
+		return nil
+	}
+
+	n, ok := v.(float64)
+	if !ok || n != float64(int(n)) || n < 1 || n > 24 {
+		return fmt.Errorf("severity_number %v must be an integer from 1 to 24", v)
+	}
+
+	text, _ := m["severity_text"].(string)
+	name := strings.TrimRight(strings.ToUpper(text), "234")
+	if name == "WARNING" {
+		name = "WARN"
+	}
+
+	if r, known := otelSeverities[name]; known && (int(n) < r[0] || int(n) > r[1]) {
+		return fmt.Errorf("severity_number %d is not consistent with severity_text %q, expected %d to %d", int(n), text, r[0], r[1])
+	}
+
+	return nil
+}
+
+func checkOTelResource(m map[string]any, attrs []string) error {
+	resource, _ := m["resource"].(map[string]any)
+
+	var errs []error
+	for _, attr := range attrs {
+		if _, ok := resource[attr]; !ok {
+			errs = append(errs, fmt.Errorf("missing resource attribute %q", attr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func checkOTelNames(m map[string]any) error {
+	var errs []error
+	for _, field := range [][2]string{{"resource", "resource attribute"}, {"attributes", "attribute"}} {
+		key, what := field[0], field[1]
+		attrs, _ := m[key].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(attrs)) {
+			if !otelName.MatchString(name) {
+				errs = append(errs, fmt.Errorf("%s %q does not follow the semantic conventions naming", what, name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
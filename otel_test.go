@@ -0,0 +1,124 @@
+package wtester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOTelPack(t *testing.T) {
+	t.Parallel()
+
+	const valid = `"trace_id":"5b8efff798038103d269b633813fc60c","span_id":"eee19b7ec3c1b174","resource":{"service.name":"api"}`
+
+	tests := map[string]struct {
+		record string
+		title  string
+		err    string
+	}{
+		"Conforming record": {
+			record: `{"severity_number":9,"severity_text":"INFO",` + valid + `,"attributes":{"http.response.status_code":200}}`,
+		},
+		"Custom severity text": {
+			record: `{"severity_number":10,"severity_text":"Information",` + valid + `}`,
+		},
+		"Uppercase trace_id": {
+			record: `{"trace_id":"5B8EFFF798038103D269B633813FC60C","resource":{"service.name":"api"}}`,
+			title:  OTelTraceIDTitle,
+			err:    `trace_id "5B8EFFF798038103D269B633813FC60C" must be 32 lowercase hex characters`,
+		},
+		"Zero trace_id": {
+			record: `{"trace_id":"00000000000000000000000000000000","resource":{"service.name":"api"}}`,
+			title:  OTelTraceIDTitle,
+			err:    `trace_id "00000000000000000000000000000000" must not be all zeros`,
+		},
+		"Short span_id": {
+			record: `{"span_id":"eee19b7e","resource":{"service.name":"api"}}`,
+			title:  OTelSpanIDTitle,
+			err:    `span_id "eee19b7e" must be 16 lowercase hex characters`,
+		},
+		"Numeric span_id": {
+			record: `{"span_id":12,"resource":{"service.name":"api"}}`,
+			title:  OTelSpanIDTitle,
+			err:    `span_id must be a string, got 12`,
+		},
+		"Severity out of range": {
+			record: `{"severity_number":25,` + valid + `}`,
+			title:  OTelSeverityTitle,
+			err:    `severity_number 25 must be an integer from 1 to 24`,
+		},
+		"Inconsistent severity": {
+			record: `{"severity_number":9,"severity_text":"ERROR2",` + valid + `}`,
+			title:  OTelSeverityTitle,
+			err:    `severity_number 9 is not consistent with severity_text "ERROR2", expected 17 to 20`,
+		},
+		"Missing service.name": {
+			record: `{"resource":{"service.version":"1.0"}}`,
+			title:  OTelResourceTitle,
+			err:    `missing resource attribute "service.name"`,
+		},
+		"Attribute names": {
+			record: `{` + valid + `,"resource":{"service.name":"api","Host":"a"},"attributes":{"userId":1,"http.route":"/","db..name":"x"}}`,
+			title:  OTelAttributeNamesTitle,
+			err: `resource attribute "Host" does not follow the semantic conventions naming
+attribute "db..name" does not follow the semantic conventions naming
+attribute "userId" does not follow the semantic conventions naming`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.ExpectPack(OTelPack())
+
+			if _, err := wt.Write([]byte(tt.record)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok || len(ve.Errs) != 1 || ve.Errs[0].Title != tt.title {
+				t.Fatalf("expected errors for %q only, got %v", tt.title, err)
+			}
+
+			if got := ve.Errs[0].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestOTelPack_IngestedRecords(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	for _, e := range wt.ExpectPack(OTelPack("service.name", "service.version")) {
+		e.OnStreams("collector")
+	}
+
+	srv := ListenHTTP(t, wt, "collector")
+	res, err := http.Post(srv.URL()+OTLPLogsPath, "application/json", strings.NewReader(otlpRequest))
+	if err != nil {
+		t.Fatalf("expected no error posting, got %v", err)
+	}
+	res.Body.Close()
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok || len(ve.Errs) != 1 || len(ve.Errs[0].Errors) != 2 {
+		t.Fatalf("expected both records to miss service.version, got %v", ve)
+	}
+
+	if got := ve.Errs[0].Errors[0].Err.Error(); got != `missing resource attribute "service.version"` {
+		t.Fatalf("expected missing service.version, got %q", got)
+	}
+}
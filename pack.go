@@ -0,0 +1,40 @@
+package wtester
+
+import (
+	"maps"
+	"slices"
+)
+
+// Pack is a ready-made set of expectations, by title, checking that
+// every record conforms to a specification. See [WTester.ExpectPack].
+type Pack map[string]Expecter
+
+// ExpectPack sets every expectation of the pack on the WTester. Each
+// one is checked on every record, see [Expect.Every], and may match no
+// record at all. The expectations are returned sorted by title, e.g.
+// to scope them with [Expect.OnStreams].
+func (l *WTester) ExpectPack(pack Pack) []*Expect {
+	expects := make([]*Expect, 0, len(pack))
+	for _, title := range slices.Sorted(maps.Keys(pack)) {
+		expects = append(expects, l.Expect(title, pack[title]).Every().WithMin(0))
+	}
+
+	return expects
+}
+
+// jsonRule is a [JSONExpecter] matching the records for which it
+// returns no error, the error explaining the failure.
+type jsonRule func(m map[string]any) error
+
+// Only for satisfy the Expecter interface.
+func (r jsonRule) Expect(actual []byte) bool {
+	return false
+}
+
+func (r jsonRule) ExpectJSON(m map[string]any) bool {
+	return r(m) == nil
+}
+
+func (r jsonRule) ExplainJSON(m map[string]any) error {
+	return r(m)
+}
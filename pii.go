@@ -0,0 +1,329 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// PIIDetector identifies one of the detectors of [PIIScan].
+type PIIDetector string
+
+const (
+	PIIEmail PIIDetector = "email"
+	PIIPhone PIIDetector = "phone"
+	PIICard  PIIDetector = "card"
+	PIIIBAN  PIIDetector = "iban"
+	PIISSN   PIIDetector = "ssn"
+	PIIIPv4  PIIDetector = "ipv4"
+	PIIIPv6  PIIDetector = "ipv6"
+	PIIJWT   PIIDetector = "jwt"
+)
+
+type piiDetector struct {
+	name PIIDetector
+	re   *regexp.Regexp
+	// valid filters out the candidates matched by re that
+	// are not what the detector looks for. Optional.
+	valid func(candidate string) bool
+	// skipTimes filters out the candidates that are the value
+	// of a time key, e.g. "ts=1697040000123" in logfmt.
+	skipTimes bool
+}
+
+// timeKeyValue matches a text ending with a time key and the
+// separator of its value, e.g. `ts=` or `"time":"`.
+var timeKeyValue = regexp.MustCompile(`(?i)(?:^|[\s{,"])(?:` + strings.Join(recordTimeKeys, "|") + `)"?\s*[=:]\s*"?$`)
+
+var piiDetectors = []piiDetector{
+	{
+		name: PIIEmail,
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		name: PIIPhone,
+		re:   regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\) ?|\b\d{3}[ .-])\d{3}[ .-]\d{4}\b|\+\d{8,15}\b`),
+	},
+	{
+		name:      PIICard,
+		re:        regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid:     validCard,
+		skipTimes: true,
+	},
+	{
+		name:  PIIIBAN,
+		re:    regexp.MustCompile(`\b[A-Z]{2}\d{2}(?:[A-Z0-9]{11,30}|(?: [A-Z0-9]{4}){2,7}(?: [A-Z0-9]{1,4})?)\b`),
+		valid: validIBAN,
+	},
+	{
+		name:  PIISSN,
+		re:    regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		valid: validSSN,
+	},
+	{
+		name: PIIIPv4,
+		re:   regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		valid: func(candidate string) bool {
+			return net.ParseIP(candidate) != nil
+		},
+	},
+	{
+		name:  PIIIPv6,
+		re:    regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}`),
+		valid: validIPv6,
+	},
+	{
+		name: PIIJWT,
+		re:   regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	},
+}
+
+// PIIScanner is an [Expecter] that matches the records free of
+// personally identifiable information. Create it with [PIIScan].
+type PIIScanner struct {
+	disabled []PIIDetector
+	allowed  []string
+}
+
+// PIIScan returns an Expecter that scans every string and number value
+// of a JSON record, or the whole record if it is not JSON, looking for
+// emails, phone numbers, Luhn-valid card numbers, IBANs, SSNs, IPv4 and
+// IPv6 addresses and JWTs. It matches the records where nothing is
+// found, use it with [Expect.Every]. Numbers are phone numbers only
+// under keys such as "phone" or "mobile".
+//
+// Unlike [ObfuscatedMatch], it does not need to know which fields may
+// hold personal data, so it catches the leaks in fields nobody listed.
+func PIIScan() *PIIScanner {
+	return &PIIScanner{}
+}
+
+// Disable turns off the given detectors.
+func (s *PIIScanner) Disable(detectors ...PIIDetector) *PIIScanner {
+	s.disabled = append(s.disabled, detectors...)
+	return s
+}
+
+// Allow ignores the given values when found, e.g. the
+// address of a test server or a well known test card.
+func (s *PIIScanner) Allow(values ...string) *PIIScanner {
+	s.allowed = append(s.allowed, values...)
+	return s
+}
+
+func (s *PIIScanner) Expect(actual []byte) bool {
+	return len(s.scan(actual)) == 0
+}
+
+// Explain returns an error naming every detector that found personal
+// data in the record, along with the JSON path where it was found.
+func (s *PIIScanner) Explain(actual []byte) error {
+	var errs []error
+	for _, f := range s.scan(actual) {
+		errs = append(errs, f)
+	}
+
+	return errors.Join(errs...)
+}
+
+type piiFinding struct {
+	detector PIIDetector
+	path     string
+	value    string
+}
+
+func (f piiFinding) Error() string {
+	if f.path == "" {
+		return fmt.Sprintf("%s detected: %q", f.detector, f.value)
+	}
+
+	return fmt.Sprintf("%s detected at %q: %q", f.detector, f.path, f.value)
+}
+
+// scan returns the personal data found in the string and number
+// values of the JSON record, or in the raw bytes if the record is
+// not JSON.
+func (s *PIIScanner) scan(actual []byte) []piiFinding {
+	if !json.Valid(actual) {
+		return s.scanString("", string(actual))
+	}
+
+	// Numbers are kept as written, float64 would round the
+	// ones with 16 digits or more, e.g. card numbers.
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(actual))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return s.scanString("", string(actual))
+	}
+
+	var findings []piiFinding
+	walkJSON(v, "", func(path string, v any) {
+		switch v := v.(type) {
+		case string:
+			findings = append(findings, s.scanString(path, v)...)
+		case json.Number:
+			findings = append(findings, s.scanNumber(path, v.String())...)
+		}
+	})
+
+	return findings
+}
+
+// phoneKey matches the paths of the phone numbers, which can be
+// logged as JSON numbers, e.g. {"phone":15551234567}.
+var phoneKey = regexp.MustCompile(`(?i)(?:^|[._-])(?:phone|mobile|msisdn|tel)(?:[._-]?(?:number|no))?(?:\[\d+\])?$`)
+
+// scanNumber is like scanString for JSON numbers. Numbers have no '+'
+// nor separators, so those of 8 to 15 digits are phone numbers when
+// their key says so.
+func (s *PIIScanner) scanNumber(path, number string) []piiFinding {
+	findings := s.scanString(path, number)
+
+	if len(number) < 8 || len(number) > 15 || strings.Trim(number, "0123456789") != "" ||
+		!phoneKey.MatchString(path) || slices.Contains(s.disabled, PIIPhone) || slices.Contains(s.allowed, number) {
+		return findings
+	}
+
+	if !slices.ContainsFunc(findings, func(f piiFinding) bool { return f.detector == PIIPhone }) {
+		findings = append(findings, piiFinding{
+			detector: PIIPhone,
+			path:     path,
+			value:    number,
+		})
+	}
+
+	return findings
+}
+
+func (s *PIIScanner) scanString(path, str string) []piiFinding {
+	var findings []piiFinding
+	for _, d := range piiDetectors {
+		if slices.Contains(s.disabled, d.name) {
+			continue
+		}
+
+		for _, loc := range d.re.FindAllStringIndex(str, -1) {
+			candidate := str[loc[0]:loc[1]]
+			if slices.Contains(s.allowed, candidate) {
+				continue
+			}
+
+			if d.skipTimes && timeKeyValue.MatchString(str[:loc[0]]) {
+				continue
+			}
+
+			if d.valid != nil && !d.valid(candidate) {
+				continue
+			}
+
+			findings = append(findings, piiFinding{
+				detector: d.name,
+				path:     path,
+				value:    candidate,
+			})
+		}
+	}
+
+	return findings
+}
+
+// digitsOnly removes the separators of a number.
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// validCard reports whether the candidate is a 13 to 19 digits
+// number passing the Luhn checksum. Only Visa issues 13 digits
+// cards, so a 13 digits run without separators must start with 4,
+// ruling out the epoch milliseconds.
+func validCard(candidate string) bool {
+	digits := digitsOnly(candidate)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	if len(digits) == 13 && digits == candidate && digits[0] != '4' {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// validIBAN reports whether the candidate passes the IBAN mod 97 check.
+func validIBAN(candidate string) bool {
+	iban := digitsOnly(candidate)
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	// Move the country code and check digits to the end and
+	// replace every letter with two digits, A = 10 ... Z = 35.
+	var sb strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&sb, "%d", r-'A'+10)
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	n, ok := new(big.Int).SetString(sb.String(), 10)
+	if !ok {
+		return false
+	}
+
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// validSSN reports whether the candidate is a US social security
+// number that could have been issued.
+func validSSN(candidate string) bool {
+	area, group, serial := candidate[:3], candidate[4:6], candidate[7:]
+	if area == "000" || area == "666" || area[0] == '9' {
+		return false
+	}
+
+	return group != "00" && serial != "0000"
+}
+
+// validIPv6 reports whether the candidate is an IPv6 address with at
+// least two non-empty groups, ruling out times and "::" separators.
+func validIPv6(candidate string) bool {
+	groups := 0
+	for _, g := range strings.Split(candidate, ":") {
+		if g != "" {
+			groups++
+		}
+	}
+
+	ip := net.ParseIP(candidate)
+	return groups >= 2 && ip != nil && ip.To4() == nil
+}
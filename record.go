@@ -0,0 +1,297 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is the normalized form of a log record, whatever the format
+// and key conventions of the logger that wrote it. See [RecordDecoder].
+type Record struct {
+	Time time.Time
+	// Level is the normalized level, [slog.LevelInfo] if the record
+	// has none. LevelText is the level as written, empty if none.
+	Level     slog.Level
+	LevelText string
+	Message   string
+	// Attrs are the fields of the record other than
+	// the time, level and message.
+	Attrs map[string]any
+	Raw   []byte
+}
+
+// Keys of the time, level and message fields, in order of precedence,
+// as written by slog, zap, zerolog, logrus and the ECS loggers, and
+// by [IngestServer] for OTLP log records.
+var (
+	recordTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+	recordLevelKeys   = []string{"level", "severity", "lvl", "log.level", "severity_text"}
+	recordMessageKeys = []string{"msg", "message", "body"}
+)
+
+// RecordDecoder decodes records into a [Record]. JSON records are
+// decoded with [ParseJSONRecord], syslog messages with
+// [ParseSyslogRecord], key=value records with [ParseLogfmtRecord]
+// and anything else with [ParseStdLogRecord].
+var RecordDecoder = NewDecoder("record", func(p []byte) (*Record, error) {
+	trimmed := bytes.TrimSpace(p)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return ParseJSONRecord(p)
+	}
+
+	if len(trimmed) > 0 && trimmed[0] == '<' {
+		if r, err := ParseSyslogRecord(p); err == nil {
+			return r, nil
+		}
+	}
+
+	if r, err := ParseLogfmtRecord(p); err == nil {
+		return r, nil
+	}
+
+	return ParseStdLogRecord(p)
+})
+
+// RecordExpecter is implemented by the expecters checking the
+// normalized [Record] of each record, so the same expectations
+// apply to every logger and format.
+type RecordExpecter = DecodedExpecter[*Record]
+
+// RecordMatch returns an Expecter that checks the [Record]
+// decoded by [RecordDecoder] with f.
+func RecordMatch(f func(r *Record) bool) *DecodeFunc[*Record] {
+	return DecodeMatch(RecordDecoder, f)
+}
+
+// ParseJSONRecord parses a JSON record. The time is read from the
+// "time", "ts", "timestamp" or "@timestamp" keys, as an RFC 3339 or
+// ISO 8601 string, e.g. "2024-01-02T12:30:45.123+0100" as written by
+// zap, or as seconds, milliseconds, microseconds or nanoseconds since
+// the Unix epoch. A time in another format is kept in the attributes
+// and the time of the record is left zero. The level is read from the "level", "severity", "lvl", "log.level" or
+// "severity_text" keys and the message from the "msg", "message" or
+// "body" keys.
+func ParseJSONRecord(p []byte) (*Record, error) {
+	var m map[string]any
+	if err := json.Unmarshal(p, &m); err != nil {
+		return nil, err
+	}
+
+	r := &Record{
+		Raw:   p,
+		Attrs: m,
+	}
+
+	r.Time = takeTime(m)
+
+	if _, v, ok := takeKey(m, recordLevelKeys); ok {
+		r.LevelText = toString(v)
+	}
+
+	if _, v, ok := takeKey(m, recordMessageKeys); ok {
+		r.Message = toString(v)
+	}
+
+	r.Level = parseLevel(r.LevelText)
+	return r, nil
+}
+
+// ParseLogfmtRecord parses a record made of key=value pairs, as written
+// by slog.TextHandler or logrus, with the same keys and time layouts as
+// [ParseJSONRecord]. Attribute values are strings.
+func ParseLogfmtRecord(p []byte) (*Record, error) {
+	pairs, err := parseLogfmt(string(p))
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		m[pair.key] = pair.value
+	}
+
+	// Bare words of plain text lines, e.g. "message queue full",
+	// parse as keys without values.
+	if !hasValue(m, recordLevelKeys) && !hasValue(m, recordMessageKeys) {
+		return nil, errors.New("not a logfmt record, no level nor message")
+	}
+
+	r := &Record{
+		Raw:   p,
+		Attrs: m,
+	}
+
+	r.Time = takeTime(m)
+
+	if _, v, ok := takeKey(m, recordLevelKeys); ok {
+		r.LevelText = v.(string)
+	}
+
+	if _, v, ok := takeKey(m, recordMessageKeys); ok {
+		r.Message = v.(string)
+	}
+
+	r.Level = parseLevel(r.LevelText)
+	return r, nil
+}
+
+// ParseStdLogRecord parses a record written by the log package. The
+// date and time written with the LstdFlags flags, with or without
+// Lmicroseconds, if present, is read as local time and the rest of the
+// line is the message. Records of the log package have no level nor
+// attributes. See [ParseStdLog] for loggers with other flags.
+func ParseStdLogRecord(p []byte) (*Record, error) {
+	r := &Record{
+		Raw:     p,
+		Message: strings.TrimRight(string(p), "\r\n"),
+	}
+
+	for _, flags := range []int{log.LstdFlags | log.Lmicroseconds, log.LstdFlags} {
+		if e, err := ParseStdLog(p, flags, ""); err == nil {
+			r.Time = e.Timestamp
+			r.Message = strings.TrimRight(e.Message, "\r")
+			break
+		}
+	}
+
+	return r, nil
+}
+
+// findKey returns the first of the keys present in m.
+func findKey(m map[string]any, keys []string) (string, bool) {
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+// hasValue reports whether the first of the keys present
+// in m has a non-empty value.
+func hasValue(m map[string]any, keys []string) bool {
+	k, ok := findKey(m, keys)
+	return ok && m[k] != ""
+}
+
+// takeKey removes the first of the keys present in m and returns it
+// with its value.
+func takeKey(m map[string]any, keys []string) (string, any, bool) {
+	k, ok := findKey(m, keys)
+	if !ok {
+		return "", nil, false
+	}
+
+	v := m[k]
+	delete(m, k)
+	return k, v, true
+}
+
+// recordTimeLayouts are the layouts of the time strings of the
+// records: RFC 3339, ISO 8601 with a ±hhmm offset, as written by
+// zap, and both with a space instead of the 'T' or without offset,
+// in which case the time is UTC.
+var recordTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// takeTime removes the first of the time keys present in m and returns
+// its value as a time. A value that is not a time is left in m and the
+// zero time is returned.
+func takeTime(m map[string]any) time.Time {
+	k, v, ok := takeKey(m, recordTimeKeys)
+	if !ok {
+		return time.Time{}
+	}
+
+	t, err := parseRecordTime(v)
+	if err != nil {
+		m[k] = v
+		return time.Time{}
+	}
+
+	return t
+}
+
+// parseRecordTime parses a string in one of the recordTimeLayouts or a
+// Unix timestamp, in seconds, milliseconds, microseconds or nanoseconds
+// depending on its magnitude.
+func parseRecordTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case string:
+		var err error
+		for _, layout := range recordTimeLayouts {
+			var t time.Time
+			if t, err = time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, err
+	case float64:
+		switch {
+		case v > 1e17:
+			return time.Unix(0, int64(v)), nil
+		case v > 1e14:
+			return time.UnixMicro(int64(v)), nil
+		case v > 1e11:
+			return time.UnixMilli(int64(v)), nil
+		default:
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+	}
+
+	return time.Time{}, errors.New("expected a string or a number")
+}
+
+// parseLevel normalizes a level name to a slog level.
+// Unknown and empty levels are Info.
+func parseLevel(text string) slog.Level {
+	switch strings.ToLower(text) {
+	case "trace":
+		return slog.LevelDebug - 4
+	case "debug":
+		return slog.LevelDebug
+	case "", "info", "information", "notice":
+		return slog.LevelInfo
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error", "err":
+		return slog.LevelError
+	case "fatal", "critical", "crit", "panic", "dpanic", "alert", "emerg", "emergency":
+		return slog.LevelError + 4
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(text)); err == nil {
+		return l
+	}
+
+	return slog.LevelInfo
+}
+
+// toString returns strings as they are and formats anything else.
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,165 @@
+package wtester
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRecordDecoder(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 1, 2, 12, 30, 45, 0, time.UTC)
+
+	tests := map[string]struct {
+		input   string
+		time    time.Time
+		level   slog.Level
+		message string
+		attrs   map[string]any
+	}{
+		"slog JSON": {
+			input:   `{"time":"2024-01-02T12:30:45Z","level":"WARN","msg":"slow query","took":"2s"}`,
+			time:    ts,
+			level:   slog.LevelWarn,
+			message: "slow query",
+			attrs:   map[string]any{"took": "2s"},
+		},
+		"zap JSON": {
+			input:   `{"level":"error","ts":1704198645,"caller":"app/main.go:12","msg":"failed"}`,
+			time:    ts,
+			level:   slog.LevelError,
+			message: "failed",
+			attrs:   map[string]any{"caller": "app/main.go:12"},
+		},
+		"zap ISO 8601 JSON": {
+			input:   `{"level":"info","ts":"2024-01-02T13:30:45.000+0100","msg":"started"}`,
+			time:    ts,
+			level:   slog.LevelInfo,
+			message: "started",
+			attrs:   map[string]any{},
+		},
+		"Time with a space": {
+			input:   `{"level":"info","time":"2024-01-02 12:30:45","msg":"started"}`,
+			time:    ts,
+			level:   slog.LevelInfo,
+			message: "started",
+			attrs:   map[string]any{},
+		},
+		"Unknown time format kept as an attribute": {
+			input:   `{"level":"info","time":"Jan 2 12:30:45","msg":"started"}`,
+			level:   slog.LevelInfo,
+			message: "started",
+			attrs:   map[string]any{"time": "Jan 2 12:30:45"},
+		},
+		"zerolog JSON": {
+			input:   `{"level":"debug","time":1704198645000,"message":"cache miss"}`,
+			time:    ts,
+			level:   slog.LevelDebug,
+			message: "cache miss",
+			attrs:   map[string]any{},
+		},
+		"zerolog JSON in microseconds": {
+			input:   `{"level":"info","time":1704198645000000,"message":"cache hit"}`,
+			time:    ts,
+			level:   slog.LevelInfo,
+			message: "cache hit",
+			attrs:   map[string]any{},
+		},
+		"ECS JSON": {
+			input:   `{"@timestamp":"2024-01-02T12:30:45Z","log.level":"fatal","message":"out of memory"}`,
+			time:    ts,
+			level:   slog.LevelError + 4,
+			message: "out of memory",
+			attrs:   map[string]any{},
+		},
+		"logfmt": {
+			input:   `time=2024-01-02T12:30:45Z level=INFO msg="user logged in" user=42` + "\n",
+			time:    ts,
+			level:   slog.LevelInfo,
+			message: "user logged in",
+			attrs:   map[string]any{"user": "42"},
+		},
+		"logfmt with an ISO 8601 time": {
+			input:   `ts=2024-01-02T13:30:45.000+0100 level=info msg=started` + "\n",
+			time:    ts,
+			level:   slog.LevelInfo,
+			message: "started",
+			attrs:   map[string]any{},
+		},
+		"Standard log": {
+			input:   "2024/01/02 12:30:45 server started\n",
+			time:    time.Date(2024, 1, 2, 12, 30, 45, 0, time.Local),
+			level:   slog.LevelInfo,
+			message: "server started",
+		},
+		"Standard log with level and message words": {
+			input:   "2024/01/02 12:30:45 body too large\n",
+			time:    time.Date(2024, 1, 2, 12, 30, 45, 0, time.Local),
+			level:   slog.LevelInfo,
+			message: "body too large",
+		},
+		"Standard log starting with a message word": {
+			input:   "message queue full\n",
+			level:   slog.LevelInfo,
+			message: "message queue full",
+		},
+		"Standard log without flags": {
+			input:   "server started: ok\n",
+			level:   slog.LevelInfo,
+			message: "server started: ok",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := RecordDecoder.Decode([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !r.Time.Equal(tt.time) {
+				t.Fatalf("expected time %v, got %v", tt.time, r.Time)
+			}
+
+			if r.Level != tt.level {
+				t.Fatalf("expected level %v, got %v", tt.level, r.Level)
+			}
+
+			if r.Message != tt.message {
+				t.Fatalf("expected message %q, got %q", tt.message, r.Message)
+			}
+
+			for k, v := range tt.attrs {
+				if r.Attrs[k] != v {
+					t.Fatalf("expected attr %s=%v, got %v", k, v, r.Attrs[k])
+				}
+			}
+
+			if len(r.Attrs) != len(tt.attrs) {
+				t.Fatalf("expected attrs %v, got %v", tt.attrs, r.Attrs)
+			}
+		})
+	}
+}
+
+func TestRecordMatch_SameExpectationAcrossFormats(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("errors have a message", RecordMatch(func(r *Record) bool {
+		return r.Level < slog.LevelError || r.Message != ""
+	})).Every()
+	wt.Expect("every record is timestamped", RecordMatch(func(r *Record) bool {
+		return !r.Time.IsZero()
+	})).Every()
+
+	wt.Write([]byte(`{"time":"2024-01-02T12:30:45Z","level":"ERROR","msg":"failed"}`))
+	wt.Write([]byte(`{"severity":"error","ts":1704198645.5,"message":"failed"}`))
+	wt.Write([]byte(`time=2024-01-02T12:30:45Z level=ERROR msg=failed`))
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package wtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// DefaultRouteKey is the field a [Router] reads the test ID from.
+const DefaultRouteKey = "test_id"
+
+type testIDKey struct{}
+
+// ContextWithTestID returns a copy of ctx carrying the test ID.
+// Records logged with it through a [Router.Handler] are routed
+// to the WTester registered under that ID.
+func ContextWithTestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, testIDKey{}, id)
+}
+
+// TestIDFromContext returns the test ID carried by ctx, if any.
+func TestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(testIDKey{}).(string)
+	return id, ok
+}
+
+// Router is an [io.Writer] shared by parallel tests, e.g. as the
+// output of a process-global logger. It writes every record to the
+// underlying writer and dispatches it to the WTester registered under
+// the test ID found in the record, so each test only validates its
+// own records. Records without a test ID, or with an unregistered one,
+// are only written to the underlying writer.
+//
+// The test ID is read from the DefaultRouteKey field of JSON records
+// or from the key=value pair of text records, as written by the slog
+// handlers. Use [Router.Handler] to inject it from the context.
+type Router struct {
+	w       io.Writer
+	muW     sync.Mutex // guards w
+	key     string
+	testers map[string]*WTester
+	mu      sync.RWMutex // guards testers
+}
+
+func NewRouter(w io.Writer) *Router {
+	return &Router{
+		w:       w,
+		key:     DefaultRouteKey,
+		testers: make(map[string]*WTester),
+	}
+}
+
+// WithKey sets the field the test ID is read from and injected into.
+// Defaults to [DefaultRouteKey].
+func (r *Router) WithKey(key string) *Router {
+	r.key = key
+	return r
+}
+
+// Register routes the records with the given test ID to wt.
+func (r *Router) Register(id string, wt *WTester) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.testers[id] = wt
+}
+
+// Unregister stops routing the records with the given test ID.
+func (r *Router) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.testers, id)
+}
+
+// Test registers a new WTester under the name of the test and
+// returns it with a context carrying the test ID. Log with that
+// context through a [Router.Handler] to route the records to the
+// returned WTester. It is unregistered on the test cleanup.
+func (r *Router) Test(t testing.TB) (*WTester, context.Context) {
+	t.Helper()
+
+	id := t.Name()
+	wt := NewWTester(io.Discard)
+
+	r.Register(id, wt)
+	t.Cleanup(func() {
+		r.Unregister(id)
+	})
+
+	return wt, ContextWithTestID(context.Background(), id)
+}
+
+// Write writes the record to the underlying writer and to the
+// WTester registered under its test ID.
+func (r *Router) Write(p []byte) (n int, err error) {
+	if id, ok := r.routeID(p); ok {
+		r.mu.RLock()
+		wt := r.testers[id]
+		r.mu.RUnlock()
+
+		if wt != nil {
+			if _, err := wt.Write(p); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	r.muW.Lock()
+	defer r.muW.Unlock()
+
+	return r.w.Write(p)
+}
+
+// routeID extracts the test ID from a JSON or key=value record.
+func (r *Router) routeID(p []byte) (string, bool) {
+	trimmed := bytes.TrimSpace(p)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var m map[string]any
+		if err := json.Unmarshal(trimmed, &m); err != nil {
+			return "", false
+		}
+
+		id, ok := m[r.key].(string)
+		return id, ok
+	}
+
+	return textValue(trimmed, r.key)
+}
+
+// textValue returns the value of key in a record made of space
+// separated key=value pairs, unquoting it if needed.
+func textValue(p []byte, key string) (string, bool) {
+	needle := []byte(key + "=")
+	for i := 0; i < len(p); {
+		j := bytes.Index(p[i:], needle)
+		if j < 0 {
+			return "", false
+		}
+
+		start := i + j
+		i = start + len(needle)
+		if start > 0 && p[start-1] != ' ' {
+			continue
+		}
+
+		rest := p[i:]
+		if len(rest) > 0 && rest[0] == '"' {
+			prefix, err := strconv.QuotedPrefix(string(rest))
+			if err != nil {
+				return "", false
+			}
+
+			v, err := strconv.Unquote(prefix)
+			return v, err == nil
+		}
+
+		if end := bytes.IndexByte(rest, ' '); end >= 0 {
+			rest = rest[:end]
+		}
+
+		return string(rest), true
+	}
+
+	return "", false
+}
+
+// Handler wraps h to add the test ID carried by the context of each
+// record, see [ContextWithTestID], as an attribute named after the
+// router key. The attribute is added to the innermost group opened
+// with WithGroup, open no groups for the records to be routed.
+func (r *Router) Handler(h slog.Handler) slog.Handler {
+	return &routeHandler{
+		Handler: h,
+		key:     r.key,
+	}
+}
+
+type routeHandler struct {
+	slog.Handler
+	key string
+}
+
+func (h *routeHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if id, ok := TestIDFromContext(ctx); ok {
+		rec = rec.Clone()
+		rec.AddAttrs(slog.String(h.key, id))
+	}
+
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h *routeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &routeHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		key:     h.key,
+	}
+}
+
+func (h *routeHandler) WithGroup(name string) slog.Handler {
+	return &routeHandler{
+		Handler: h.Handler.WithGroup(name),
+		key:     h.key,
+	}
+}
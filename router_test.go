@@ -0,0 +1,76 @@
+package wtester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestRouter_DispatchesRecordsToTheirTest(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter(io.Discard)
+	logger := slog.New(router.Handler(slog.NewJSONHandler(router, nil)))
+
+	for i := range 3 {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			wt, ctx := router.Test(t)
+			wt.Expect("own records only", StringMatch(fmt.Sprintf(`"n":%d`, i), false)).Every().WithMin(2).WithMax(2)
+
+			logger.InfoContext(ctx, "handling", "n", i)
+			logger.InfoContext(ctx, "handled", "n", i)
+			logger.InfoContext(context.Background(), "not routed", "n", -1)
+
+			if err := wt.Validate(); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRouter_RoutesTextRecords(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter(io.Discard).WithKey("tid")
+	logger := slog.New(router.Handler(slog.NewTextHandler(router, nil)))
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("routed", StringMatch("msg=routed", false)).Every().WithMin(1)
+	router.Register("my test", wt)
+
+	logger.InfoContext(ContextWithTestID(context.Background(), "my test"), "routed")
+	logger.InfoContext(ContextWithTestID(context.Background(), "other test"), "ignored")
+	logger.Info("ignored")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestTextValue(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input    string
+		expected string
+		found    bool
+	}{
+		"Plain value":        {input: `level=INFO test_id=abc msg=hi`, expected: "abc", found: true},
+		"Quoted value":       {input: `test_id="a b" msg=hi`, expected: "a b", found: true},
+		"Key suffix ignored": {input: `my_test_id=x test_id=y`, expected: "y", found: true},
+		"Missing key":        {input: `level=INFO msg=hi`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, ok := textValue([]byte(tt.input), "test_id")
+			if ok != tt.found || v != tt.expected {
+				t.Fatalf("expected %q %v, got %q %v", tt.expected, tt.found, v, ok)
+			}
+		})
+	}
+}
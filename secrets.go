@@ -0,0 +1,259 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SecretsTitle is the title the leaks of the secrets registered
+// with [WTester.ForbidSecrets] are reported under.
+const SecretsTitle = "forbidden secrets"
+
+// redactedMarker replaces the leaked secrets in the written bytes.
+const redactedMarker = "[REDACTED]"
+
+// autoMinSubstring makes the length of the shortest truncated form
+// of a secret that is considered a leak half of the secret, at least
+// minTruncated bytes. Shorter windows match ordinary words, e.g.
+// "password" in both "password-for-tests" and "password-reset".
+const (
+	autoMinSubstring = -1
+	minTruncated     = 12
+)
+
+// SecretGuard holds the secrets that must never be written
+// to a [WTester]. Create it with [WTester.ForbidSecrets].
+type SecretGuard struct {
+	secrets      []secret
+	minSubstring int
+}
+
+type secret struct {
+	raw      []byte
+	variants []secretVariant
+}
+
+// secretVariant is an encoded form of a secret.
+type secretVariant struct {
+	encoding string
+	value    []byte
+}
+
+// ForbidSecrets registers values that must never be written to the
+// WTester, e.g. the credentials and tokens used by the test. A value is
+// leaked if it appears raw, base64 encoded (standard or URL alphabet,
+// also when embedded in a longer base64 string), URL encoded, hex
+// encoded, JSON escaped or truncated, see [SecretGuard.WithMinSubstring].
+//
+// Secrets are checked on Write before the bytes reach the underlying
+// writer. The leaked forms are redacted from the bytes written and
+// checked against the other expectations, so they do not end up in
+// logs, CI artifacts or validation reports, and are reported under
+// [SecretsTitle] naming the secret by its registration order.
+func (l *WTester) ForbidSecrets(values ...string) *SecretGuard {
+	if l.secrets == nil {
+		l.secrets = &SecretGuard{
+			minSubstring: autoMinSubstring,
+		}
+	}
+
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		l.secrets.secrets = append(l.secrets.secrets, secret{
+			raw:      []byte(v),
+			variants: secretVariants(v),
+		})
+	}
+
+	return l.secrets
+}
+
+// WithMinSubstring sets the length of the shortest substring of a
+// secret that is considered a leak, to catch truncated forms of it.
+// Defaults to half of each secret, at least 12 bytes, so secrets of
+// 12 bytes or less are only looked for whole. Shorter substrings catch
+// more truncations but also flag and redact unrelated text sharing a
+// word with a secret. Use 0 to only look for the whole secrets.
+func (g *SecretGuard) WithMinSubstring(n int) *SecretGuard {
+	g.minSubstring = max(n, 0)
+	return g
+}
+
+// secretVariants returns the encoded forms of v, without duplicates.
+func secretVariants(v string) []secretVariant {
+	variants := []secretVariant{{encoding: "raw", value: []byte(v)}}
+	add := func(encoding, value string) {
+		if len(value) == 0 {
+			return
+		}
+
+		for _, sv := range variants {
+			if string(sv.value) == value {
+				return
+			}
+		}
+
+		variants = append(variants, secretVariant{encoding: encoding, value: []byte(value)})
+	}
+
+	for _, enc := range base64Aligned([]byte(v), base64.RawStdEncoding) {
+		add("base64", enc)
+	}
+	for _, enc := range base64Aligned([]byte(v), base64.RawURLEncoding) {
+		add("base64url", enc)
+	}
+
+	add("URL encoded", url.QueryEscape(v))
+	add("URL encoded", url.PathEscape(v))
+	add("hex", hex.EncodeToString([]byte(v)))
+	add("hex", strings.ToUpper(hex.EncodeToString([]byte(v))))
+
+	if b, err := json.Marshal(v); err == nil {
+		add("JSON escaped", string(b[1:len(b)-1]))
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err == nil {
+		b := bytes.TrimSpace(buf.Bytes())
+		add("JSON escaped", string(b[1:len(b)-1]))
+	}
+
+	return variants
+}
+
+// base64Aligned returns the base64 characters that only depend on v
+// for each of the three byte alignments v can have when encoded as
+// part of a longer value.
+func base64Aligned(v []byte, enc *base64.Encoding) []string {
+	var out []string
+	for k := range 3 {
+		encoded := enc.EncodeToString(append(make([]byte, k), v...))
+
+		// Characters encode 6 bits each, skip the ones holding
+		// bits of the k leading bytes or of what follows v.
+		start := (8*k + 5) / 6
+		end := 8 * (k + len(v)) / 6
+		if end-start < 4 {
+			continue
+		}
+
+		out = append(out, encoded[start:end])
+	}
+
+	return out
+}
+
+// check returns the bytes with every leaked form of the secrets
+// redacted and an error describing the leaks, if any.
+func (g *SecretGuard) check(p []byte) ([]byte, error) {
+	var (
+		ranges [][2]int
+		errs   []error
+	)
+
+	for i, s := range g.secrets {
+		for _, v := range s.variants {
+			found := indexAll(p, v.value)
+			if len(found) == 0 {
+				continue
+			}
+
+			ranges = append(ranges, found...)
+			errs = append(errs, fmt.Errorf("secret #%d leaked %s", i+1, v.encoding))
+		}
+
+		window := g.minSubstring
+		if window == autoMinSubstring {
+			window = max(minTruncated, (len(s.raw)+1)/2)
+		}
+
+		if window <= 0 || len(s.raw) <= window {
+			continue
+		}
+
+		// Any substring longer than the window contains
+		// a substring of exactly the window length.
+		var truncated [][2]int
+		for start := 0; start+window <= len(s.raw); start++ {
+			truncated = append(truncated, indexAll(p, s.raw[start:start+window])...)
+		}
+
+		if len(truncated) > 0 && !coveredBy(truncated, ranges) {
+			errs = append(errs, fmt.Errorf("secret #%d leaked truncated", i+1))
+		}
+
+		ranges = append(ranges, truncated...)
+	}
+
+	if len(errs) == 0 {
+		return p, nil
+	}
+
+	return redact(p, ranges), errors.Join(errs...)
+}
+
+// indexAll returns the ranges of every occurrence of sub in p.
+func indexAll(p, sub []byte) [][2]int {
+	var ranges [][2]int
+	for offset := 0; ; {
+		i := bytes.Index(p[offset:], sub)
+		if i < 0 {
+			return ranges
+		}
+
+		start := offset + i
+		ranges = append(ranges, [2]int{start, start + len(sub)})
+		offset = start + 1
+	}
+}
+
+// coveredBy reports whether every range in ranges is
+// contained in one of the ranges in by.
+func coveredBy(ranges, by [][2]int) bool {
+	for _, r := range ranges {
+		covered := slices.ContainsFunc(by, func(b [2]int) bool {
+			return b[0] <= r[0] && r[1] <= b[1]
+		})
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redact returns a copy of p with the ranges replaced by a marker.
+// Overlapping ranges are merged first.
+func redact(p []byte, ranges [][2]int) []byte {
+	slices.SortFunc(ranges, func(a, b [2]int) int {
+		return a[0] - b[0]
+	})
+
+	var out []byte
+	last := 0
+	for i := 0; i < len(ranges); {
+		start, end := ranges[i][0], ranges[i][1]
+		for i++; i < len(ranges) && ranges[i][0] <= end; i++ {
+			end = max(end, ranges[i][1])
+		}
+
+		out = append(out, p[last:start]...)
+		out = append(out, redactedMarker...)
+		last = end
+	}
+
+	return append(out, p[last:]...)
+}
@@ -0,0 +1,126 @@
+package wtester
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWTester_ForbidSecrets(t *testing.T) {
+	t.Parallel()
+
+	const secret = `s3cr3t/t0ken+"value"`
+
+	tests := map[string]struct {
+		input    string
+		encoding string
+	}{
+		"Raw": {
+			input:    "token=" + secret,
+			encoding: "raw",
+		},
+		"Base64": {
+			input:    "payload " + base64.StdEncoding.EncodeToString([]byte(secret)),
+			encoding: "base64",
+		},
+		"Base64 embedded in basic auth": {
+			input:    "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("admin:"+secret)),
+			encoding: "base64",
+		},
+		"URL encoded": {
+			input:    "GET /login?token=" + url.QueryEscape(secret),
+			encoding: "URL encoded",
+		},
+		"Hex": {
+			input:    "dump " + hex.EncodeToString([]byte(secret)),
+			encoding: "hex",
+		},
+		"JSON escaped": {
+			input:    fmt.Sprintf(`{"token":%q}`, secret),
+			encoding: "JSON escaped",
+		},
+		"Truncated": {
+			input:    "token=" + secret[:12] + "...",
+			encoding: "truncated",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := new(bytes.Buffer)
+			wt := NewWTester(buf)
+			wt.ForbidSecrets(secret)
+
+			n, err := wt.Write([]byte(tt.input))
+			if err != nil || n != len(tt.input) {
+				t.Fatalf("expected %d bytes written, got %d %v", len(tt.input), n, err)
+			}
+
+			if !strings.Contains(buf.String(), redactedMarker) || buf.String() == tt.input {
+				t.Fatalf("expected the leak to be redacted, got %q", buf.String())
+			}
+
+			ve, ok := wt.Validate().(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors")
+			}
+
+			rec := ve.Errs[0].Errors[0]
+			if ve.Errs[0].Title != SecretsTitle {
+				t.Fatalf("expected title %q, got %q", SecretsTitle, ve.Errs[0].Title)
+			}
+
+			if !strings.Contains(rec.Err.Error(), "secret #1 leaked "+tt.encoding) {
+				t.Fatalf("expected %s leak, got %q", tt.encoding, rec.Err)
+			}
+
+			if !bytes.Equal(rec.Bytes, buf.Bytes()) {
+				t.Fatalf("expected the report to hold the redacted bytes, got %q", rec.Bytes)
+			}
+		})
+	}
+}
+
+func TestWTester_ForbidSecretsIgnoresShortSubstrings(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	wt := NewWTester(buf)
+	wt.ForbidSecrets("correct-horse-battery").WithMinSubstring(10)
+
+	input := "the correct horse is in the battery"
+	wt.Write([]byte(input))
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if buf.String() != input {
+		t.Fatalf("expected %q to be written, got %q", input, buf.String())
+	}
+}
+
+func TestWTester_ForbidSecretsIgnoresSharedWords(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	wt := NewWTester(buf)
+	wt.ForbidSecrets("password-for-tests")
+
+	input := `{"msg":"password-reset sent"}`
+	wt.Write([]byte(input))
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if buf.String() != input {
+		t.Fatalf("expected %q to be written, got %q", input, buf.String())
+	}
+}
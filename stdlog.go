@@ -0,0 +1,141 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileLinePattern matches the file:line header written by the
+// Lshortfile and Llongfile flags.
+var fileLinePattern = regexp.MustCompile(`^(.*?):(\d+): `)
+
+// StdLogEntry is a record written by a [log.Logger], split into the
+// parts of its header and its message. The parts not enabled by the
+// logger flags are empty.
+type StdLogEntry struct {
+	Prefix string
+	// Date and Time are as written, e.g. "2009/01/23" and
+	// "01:23:23.123123". Timestamp is them parsed, in UTC with the
+	// LUTC flag and in local time otherwise.
+	Date      string
+	Time      string
+	Timestamp time.Time
+	File      string
+	Line      int
+	Message   string
+}
+
+// StdLogDecoder returns a Decoder splitting the records written by a
+// [log.Logger] with the given flags and prefix, see [ParseStdLog].
+// Use it with [DecodeMatch] to check the message alone, or the header
+// format, without matching the whole line with regular expressions.
+func StdLogDecoder(flags int, prefix string) *Decoder[*StdLogEntry] {
+	name := fmt.Sprintf("stdlog(%d,%q)", flags, prefix)
+	return NewDecoder(name, func(p []byte) (*StdLogEntry, error) {
+		return ParseStdLog(p, flags, prefix)
+	})
+}
+
+// ParseStdLog splits a record written by a [log.Logger] with the given
+// flags and prefix. The header is expected to be exactly as the logger
+// writes it, an error is returned otherwise.
+func ParseStdLog(p []byte, flags int, prefix string) (*StdLogEntry, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+	e := &StdLogEntry{}
+
+	takePrefix := func() error {
+		if !strings.HasPrefix(line, prefix) {
+			return fmt.Errorf("expected prefix %q", prefix)
+		}
+
+		e.Prefix = prefix
+		line = line[len(prefix):]
+		return nil
+	}
+
+	if flags&log.Lmsgprefix == 0 {
+		if err := takePrefix(); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
+		if err := e.parseTimestamp(&line, flags); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		m := fileLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, errors.New("expected file:line")
+		}
+
+		e.File = m[1]
+		e.Line, _ = strconv.Atoi(m[2])
+		line = line[len(m[0]):]
+
+		if flags&log.Lshortfile != 0 && strings.Contains(e.File, "/") {
+			return nil, fmt.Errorf("expected a short file name, got %q", e.File)
+		}
+	}
+
+	if flags&log.Lmsgprefix != 0 {
+		if err := takePrefix(); err != nil {
+			return nil, err
+		}
+	}
+
+	e.Message = line
+	return e, nil
+}
+
+// parseTimestamp consumes the date and time of the header.
+func (e *StdLogEntry) parseTimestamp(line *string, flags int) error {
+	var layout string
+
+	if flags&log.Ldate != 0 {
+		const dateLayout = "2006/01/02"
+		if len(*line) < len(dateLayout)+1 {
+			return errors.New("expected a date")
+		}
+
+		e.Date = (*line)[:len(dateLayout)]
+		*line = (*line)[len(dateLayout)+1:]
+		layout = dateLayout
+	}
+
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		timeLayout := "15:04:05"
+		if flags&log.Lmicroseconds != 0 {
+			timeLayout += ".000000"
+		}
+
+		if len(*line) < len(timeLayout)+1 {
+			return errors.New("expected a time")
+		}
+
+		e.Time = (*line)[:len(timeLayout)]
+		*line = (*line)[len(timeLayout)+1:]
+		layout = strings.TrimSpace(layout + " " + timeLayout)
+	}
+
+	loc := time.Local
+	if flags&log.LUTC != 0 {
+		loc = time.UTC
+	}
+
+	value := strings.TrimSpace(e.Date + " " + e.Time)
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", value, err)
+	}
+
+	e.Timestamp = t
+	return nil
+}
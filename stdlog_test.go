@@ -0,0 +1,106 @@
+package wtester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+)
+
+func ExampleStdLogDecoder() {
+	wt := NewWTester(io.Discard)
+
+	flags := log.LstdFlags | log.Lshortfile | log.Lmsgprefix
+	dec := StdLogDecoder(flags, "[payments] ")
+
+	wt.Expect("Retry message", DecodeMatch(dec, func(e *StdLogEntry) bool {
+		return e.Message == "retrying req 144414"
+	})).Every()
+	wt.Expect("Logged from the tests", DecodeMatch(dec, func(e *StdLogEntry) bool {
+		return e.File == "stdlog_test.go"
+	})).Every()
+
+	logger := log.New(wt, "[payments] ", flags)
+	logger.Printf("retrying req 144414")
+
+	err := wt.Validate()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Println("No errors.")
+	}
+
+	// Output: No errors.
+}
+
+func TestParseStdLog(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		flags  int
+		prefix string
+	}{
+		"No flags":                 {flags: 0},
+		"Standard flags":           {flags: log.LstdFlags},
+		"Microseconds in UTC":      {flags: log.Ldate | log.Lmicroseconds | log.LUTC},
+		"Short file and prefix":    {flags: log.LstdFlags | log.Lshortfile, prefix: "app: "},
+		"Long file and msg prefix": {flags: log.Ltime | log.Llongfile | log.Lmsgprefix, prefix: "[db] "},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := new(bytes.Buffer)
+			log.New(buf, tt.prefix, tt.flags).Print("connection: refused")
+
+			e, err := ParseStdLog(buf.Bytes(), tt.flags, tt.prefix)
+			if err != nil {
+				t.Fatalf("expected no error parsing %q, got %v", buf.String(), err)
+			}
+
+			if e.Message != "connection: refused" {
+				t.Fatalf("expected message %q, got %q", "connection: refused", e.Message)
+			}
+
+			if e.Prefix != tt.prefix {
+				t.Fatalf("expected prefix %q, got %q", tt.prefix, e.Prefix)
+			}
+
+			hasTime := tt.flags&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0
+			if hasTime == e.Timestamp.IsZero() {
+				t.Fatalf("expected timestamp %v, got %v", hasTime, e.Timestamp)
+			}
+
+			hasFile := tt.flags&(log.Lshortfile|log.Llongfile) != 0
+			if hasFile && (e.File == "" || e.Line == 0) {
+				t.Fatalf("expected file and line, got %q:%d", e.File, e.Line)
+			}
+		})
+	}
+}
+
+func TestParseStdLog_RejectsOtherFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		flags  int
+		prefix string
+	}{
+		"Missing prefix":        {input: "2024/01/02 12:30:45 hi\n", flags: log.LstdFlags, prefix: "app: "},
+		"Missing date":          {input: "hi\n", flags: log.LstdFlags},
+		"Invalid date":          {input: "2024-01-02 12:30:45 hi\n", flags: log.LstdFlags},
+		"Missing file":          {input: "2024/01/02 12:30:45 hi\n", flags: log.LstdFlags | log.Lshortfile},
+		"Long file, short flag": {input: "/app/main.go:12: hi\n", flags: log.Lshortfile},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseStdLog([]byte(tt.input), tt.flags, tt.prefix); err == nil {
+				t.Fatalf("expected an error parsing %q", tt.input)
+			}
+		})
+	}
+}
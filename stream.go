@@ -0,0 +1,94 @@
+package wtester
+
+import (
+	"bytes"
+	"io"
+	"slices"
+)
+
+// Names of the streams used for the standard output and
+// standard error of a process.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// streamWriter is an [io.Writer] that tags every record
+// written through it with the name of a stream.
+type streamWriter struct {
+	l    *WTester
+	name string
+}
+
+func (s *streamWriter) Write(p []byte) (n int, err error) {
+	return s.l.write(s.name, p)
+}
+
+// Stream returns an [io.Writer] that feeds the WTester with the
+// records of the named stream. It allows for a single WTester to
+// capture several sources, e.g. stdout and stderr, or the logs of
+// different services, and still tell them apart.
+//
+// The records are checked against the expectations scoped to the
+// stream with [Expect.OnStreams] and against the unscoped ones, and
+// then written to the underlying writer. Failing records report the
+// stream they came from.
+func (l *WTester) Stream(name string) io.Writer {
+	return &streamWriter{
+		l:    l,
+		name: name,
+	}
+}
+
+// lineWriter frames the bytes written to it into lines, writing
+// every complete line, including its trailing newline, to w with
+// a single Write call.
+type lineWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (n int, err error) {
+	lw.buf = append(lw.buf, p...)
+
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := slices.Clone(lw.buf[:i+1])
+		lw.buf = lw.buf[i+1:]
+
+		if _, err := lw.w.Write(line); err != nil {
+			return len(p), err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the last line if it was not terminated by a newline.
+func (lw *lineWriter) Flush() error {
+	if len(lw.buf) == 0 {
+		return nil
+	}
+
+	line := lw.buf
+	lw.buf = nil
+
+	_, err := lw.w.Write(line)
+	return err
+}
+
+// Scan reads r until EOF and writes every line read to the WTester
+// with a single Write call per line. Use it to feed the WTester with
+// sources that do not frame records themselves, like files or pipes.
+func (l *WTester) Scan(r io.Reader) error {
+	lw := &lineWriter{w: l}
+	if _, err := io.Copy(lw, r); err != nil {
+		return err
+	}
+
+	return lw.Flush()
+}
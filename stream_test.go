@@ -0,0 +1,58 @@
+package wtester
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWTester_StreamScopedExpectations(t *testing.T) {
+	t.Parallel()
+
+	buf := new(bytes.Buffer)
+	wt := NewWTester(buf)
+
+	wt.Expect("stdout is JSON", PrefixMatch("{")).Every().OnStreams("stdout")
+	wt.Expect("one stderr warning", StringMatch("warning", false)).WithMin(1).WithMax(1).OnStreams("stderr")
+	wt.Expect("everything utf8", ValidUTF8()).Every()
+
+	stdout := wt.Stream("stdout")
+	stderr := wt.Stream("stderr")
+
+	io.WriteString(stdout, `{"msg":"started"}`)
+	io.WriteString(stderr, "warning: disk almost full")
+	io.WriteString(wt, "warning: not on a stream")
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `{"msg":"started"}warning: disk almost fullwarning: not on a stream`
+	if buf.String() != expected {
+		t.Fatalf("expected underlying writer to contain %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWTester_StreamIsReportedOnFailure(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("stdout is JSON", PrefixMatch("{")).Every().OnStreams("stdout")
+
+	io.WriteString(wt.Stream("stdout"), "plain text")
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	rec := ve.Errs[0].Errors[0]
+	if rec.Stream != "stdout" {
+		t.Fatalf("expected stream %q, got %q", "stdout", rec.Stream)
+	}
+
+	if !strings.Contains(ve.Error(), "[stdout] plain text") {
+		t.Fatalf("expected report to contain the stream, got %q", ve.Error())
+	}
+}
@@ -0,0 +1,599 @@
+package wtester
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SyslogFormat is the RFC a syslog message follows.
+type SyslogFormat int
+
+const (
+	SyslogRFC5424 SyslogFormat = iota
+	SyslogRFC3164
+)
+
+func (f SyslogFormat) String() string {
+	if f == SyslogRFC3164 {
+		return "RFC 3164"
+	}
+
+	return "RFC 5424"
+}
+
+// Names of the header fields of a syslog message, see
+// [SyslogConformance.RequireFields].
+const (
+	SyslogHostname = "hostname"
+	SyslogAppName  = "app_name"
+	SyslogProcID   = "procid"
+	SyslogMsgID    = "msgid"
+)
+
+// Maximum lengths of the RFC 5424 header fields.
+var syslogFieldLengths = map[string]int{
+	SyslogHostname: 255,
+	SyslogAppName:  48,
+	SyslogProcID:   128,
+	SyslogMsgID:    32,
+}
+
+// rfc3339Syslog is the RFC 5424 TIMESTAMP: an RFC 3339 time with
+// at most 6 fraction digits and a mandatory offset.
+var rfc3339Syslog = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d{1,6})?(Z|[+-]\d{2}:\d{2})$`)
+
+// rfc3164Header matches the RFC 3164 TIMESTAMP HOSTNAME and the
+// optional TAG[PID]: of the message.
+var rfc3164Header = regexp.MustCompile(`^([A-Z][a-z]{2} [ 0-9]\d \d{2}:\d{2}:\d{2}) (\S+)(?: |$)(?:([^\s:\[]{1,32})(?:\[([^\]\s]+)\])?: ?)?`)
+
+// SyslogMessage is a parsed syslog message. The header fields set
+// to the NILVALUE "-" are empty.
+type SyslogMessage struct {
+	Format   SyslogFormat
+	Priority int
+	Facility int
+	Severity int
+	// Version is always 0 for RFC 3164 messages.
+	Version int
+	// Timestamp is zero when the NILVALUE is used. RFC 3164 timestamps
+	// have no year nor offset, they are parsed as year 0 in UTC.
+	Timestamp      time.Time
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData []SDElement
+	Message        string
+}
+
+// SDElement is an RFC 5424 structured data element.
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// SDParam is a parameter of a structured data element.
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// Fields returns the fields of the message as a map, with the keys
+// "facility", "severity", "hostname", "app_name", "procid", "msgid",
+// "msg", and "sd" holding the structured data as a map of element IDs
+// to maps of parameters. Empty fields are omitted. See
+// [SyslogJSONDecoder] to check them with the [JSONExpecter]
+// implementations.
+func (m *SyslogMessage) Fields() map[string]any {
+	fields := map[string]any{
+		"facility": float64(m.Facility),
+		"severity": float64(m.Severity),
+	}
+
+	for k, v := range map[string]string{
+		SyslogHostname: m.Hostname,
+		SyslogAppName:  m.AppName,
+		SyslogProcID:   m.ProcID,
+		SyslogMsgID:    m.MsgID,
+		"msg":          m.Message,
+	} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+
+	if len(m.StructuredData) > 0 {
+		sd := make(map[string]any)
+		for _, e := range m.StructuredData {
+			params := make(map[string]any)
+			for _, p := range e.Params {
+				params[p.Name] = p.Value
+			}
+			sd[e.ID] = params
+		}
+		fields["sd"] = sd
+	}
+
+	return fields
+}
+
+// field returns the header field by name.
+func (m *SyslogMessage) field(name string) string {
+	switch name {
+	case SyslogHostname:
+		return m.Hostname
+	case SyslogAppName:
+		return m.AppName
+	case SyslogProcID:
+		return m.ProcID
+	case SyslogMsgID:
+		return m.MsgID
+	}
+
+	return ""
+}
+
+// SyslogDecoder returns a Decoder parsing syslog messages, see
+// [ParseSyslog].
+func SyslogDecoder(strict bool) *Decoder[*SyslogMessage] {
+	name := "syslog"
+	if strict {
+		name = "syslog-strict"
+	}
+
+	return NewDecoder(name, func(p []byte) (*SyslogMessage, error) {
+		return ParseSyslog(p, strict)
+	})
+}
+
+// SyslogJSONDecoder returns a [JSONDecoder] parsing syslog messages,
+// see [ParseSyslog], and returning their [SyslogMessage.Fields]. See
+// [WTester.SyslogJSON].
+func SyslogJSONDecoder(strict bool) *Decoder[map[string]any] {
+	return NewDecoder("json", func(p []byte) (map[string]any, error) {
+		m, err := ParseSyslog(p, strict)
+		if err != nil {
+			return nil, err
+		}
+
+		return m.Fields(), nil
+	})
+}
+
+// SyslogJSON makes the WTester decode the records checked by the
+// [JSONExpecter] implementations with [SyslogJSONDecoder], so they
+// check the fields of syslog messages, e.g. a [MaskPolicy] rule on
+// "sd.auth.password". Records that cannot be parsed are reported
+// once under the "json decoder" title and skipped by those
+// expectations.
+func (l *WTester) SyslogJSON(strict bool) *WTester {
+	l.RegisterDecoder(SyslogJSONDecoder(strict))
+	return l
+}
+
+// ParseSyslog parses an RFC 5424 message, or an RFC 3164 one if it has
+// no version. The PRI must always be valid. In strict mode, every rule
+// of the RFC is enforced: field lengths and characters, the timestamp
+// format and the structured data syntax. Otherwise, what can be parsed
+// is returned as is.
+func ParseSyslog(p []byte, strict bool) (*SyslogMessage, error) {
+	s := strings.TrimRight(string(p), "\r\n")
+	m := &SyslogMessage{}
+
+	rest, err := m.parsePRI(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' {
+		if i := strings.IndexByte(rest, ' '); i > 0 {
+			if v, err := strconv.Atoi(rest[:i]); err == nil {
+				m.Version = v
+				return m, m.parse5424(rest[i+1:], strict)
+			}
+		}
+	}
+
+	m.Format = SyslogRFC3164
+	return m, m.parse3164(rest, strict)
+}
+
+// parsePRI parses "<PRI>" and returns what follows.
+func (m *SyslogMessage) parsePRI(s string) (string, error) {
+	end := strings.IndexByte(s, '>')
+	if !strings.HasPrefix(s, "<") || end < 2 || end > 4 {
+		return "", errors.New("expected <PRI>")
+	}
+
+	digits := s[1:end]
+	pri, err := strconv.Atoi(digits)
+	if err != nil || pri < 0 || pri > 191 || (len(digits) > 1 && digits[0] == '0') {
+		return "", fmt.Errorf("invalid PRI %q, expected 0 to 191", digits)
+	}
+
+	m.Priority = pri
+	m.Facility = pri / 8
+	m.Severity = pri % 8
+	return s[end+1:], nil
+}
+
+func (m *SyslogMessage) parse5424(s string, strict bool) error {
+	if strict && m.Version != 1 {
+		return fmt.Errorf("unsupported version %d", m.Version)
+	}
+
+	parts := strings.SplitN(s, " ", 6)
+	if len(parts) < 6 {
+		return errors.New("expected TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA")
+	}
+
+	if ts := parts[0]; ts != "-" {
+		if strict && !rfc3339Syslog.MatchString(ts) {
+			return fmt.Errorf("invalid timestamp %q", ts)
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", ts)
+		}
+		m.Timestamp = t
+	}
+
+	fields := []struct {
+		name string
+		dst  *string
+	}{
+		{SyslogHostname, &m.Hostname},
+		{SyslogAppName, &m.AppName},
+		{SyslogProcID, &m.ProcID},
+		{SyslogMsgID, &m.MsgID},
+	}
+
+	for i, f := range fields {
+		v := parts[i+1]
+		if strict {
+			if err := checkSyslogField(f.name, v); err != nil {
+				return err
+			}
+		}
+
+		if v != "-" {
+			*f.dst = v
+		}
+	}
+
+	rest, err := m.parseSD(parts[5], strict)
+	if err != nil {
+		return err
+	}
+
+	if rest != "" {
+		if rest[0] != ' ' {
+			return errors.New("expected a space before MSG")
+		}
+		rest = rest[1:]
+	}
+
+	if strings.HasPrefix(rest, "\ufeff") {
+		rest = rest[len("\ufeff"):]
+		if strict && !utf8.ValidString(rest) {
+			return errors.New("invalid UTF-8 in MSG starting with a BOM")
+		}
+	}
+
+	m.Message = rest
+	return nil
+}
+
+// checkSyslogField checks the length and characters of a header field.
+func checkSyslogField(name, v string) error {
+	if v == "" || len(v) > syslogFieldLengths[name] {
+		return fmt.Errorf("invalid %s, expected 1 to %d characters", name, syslogFieldLengths[name])
+	}
+
+	for i := 0; i < len(v); i++ {
+		if v[i] < 33 || v[i] > 126 {
+			return fmt.Errorf("invalid %s, character %q is not printable US-ASCII", name, v[i])
+		}
+	}
+
+	return nil
+}
+
+// parseSD parses the structured data and returns what follows.
+func (m *SyslogMessage) parseSD(s string, strict bool) (string, error) {
+	if strings.HasPrefix(s, "-") {
+		return s[1:], nil
+	}
+
+	if !strings.HasPrefix(s, "[") {
+		return "", errors.New("expected STRUCTURED-DATA")
+	}
+
+	for strings.HasPrefix(s, "[") {
+		var (
+			e   SDElement
+			err error
+		)
+
+		e.ID, s, err = parseSDName(s[1:], "SD-ID", strict)
+		if err != nil {
+			return "", err
+		}
+
+		for strings.HasPrefix(s, " ") {
+			var p SDParam
+			p.Name, s, err = parseSDName(s[1:], "PARAM-NAME", strict)
+			if err != nil {
+				return "", err
+			}
+
+			if !strings.HasPrefix(s, `="`) {
+				return "", fmt.Errorf("expected =\"value\" after %q", p.Name)
+			}
+
+			p.Value, s, err = parseSDValue(s[2:], strict)
+			if err != nil {
+				return "", fmt.Errorf("invalid value of %q: %w", p.Name, err)
+			}
+
+			e.Params = append(e.Params, p)
+		}
+
+		if !strings.HasPrefix(s, "]") {
+			return "", fmt.Errorf("expected ] closing %q", e.ID)
+		}
+
+		s = s[1:]
+		m.StructuredData = append(m.StructuredData, e)
+	}
+
+	return s, nil
+}
+
+// parseSDName parses an SD-NAME and returns what follows.
+func parseSDName(s, what string, strict bool) (string, string, error) {
+	end := strings.IndexAny(s, "= ]\"")
+	if end < 0 {
+		end = len(s)
+	}
+
+	name := s[:end]
+	if name == "" {
+		return "", "", fmt.Errorf("empty %s", what)
+	}
+
+	if strict {
+		if len(name) > 32 {
+			return "", "", fmt.Errorf("invalid %s %q, expected at most 32 characters", what, name)
+		}
+
+		for i := 0; i < len(name); i++ {
+			if name[i] < 33 || name[i] > 126 {
+				return "", "", fmt.Errorf("invalid %s %q, character %q is not printable US-ASCII", what, name, name[i])
+			}
+		}
+	}
+
+	return name, s[end:], nil
+}
+
+// parseSDValue parses a PARAM-VALUE up to its closing quote, unescaping
+// it, and returns what follows.
+func parseSDValue(s string, strict bool) (string, string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return sb.String(), s[i+1:], nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+				i++
+				sb.WriteByte(s[i])
+				continue
+			}
+
+			sb.WriteByte(c)
+		case ']':
+			if strict {
+				return "", "", errors.New("unescaped ]")
+			}
+
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", "", errors.New("missing closing quote")
+}
+
+func (m *SyslogMessage) parse3164(s string, strict bool) error {
+	h := rfc3164Header.FindStringSubmatch(s)
+	if h == nil {
+		if strict {
+			return errors.New("expected an RFC 3164 TIMESTAMP HOSTNAME header")
+		}
+
+		m.Message = s
+		return nil
+	}
+
+	t, err := time.Parse(time.Stamp, h[1])
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", h[1])
+	}
+
+	m.Timestamp = t
+	m.Hostname = h[2]
+	m.AppName = h[3]
+	m.ProcID = h[4]
+	m.Message = s[len(h[0]):]
+
+	if strict && m.AppName == "" {
+		return errors.New("expected a TAG")
+	}
+
+	return nil
+}
+
+// SyslogConformance is a [DecodedExpecter] checking the fields of the
+// syslog messages. Create it with [SyslogCheck].
+type SyslogConformance struct {
+	dec        *Decoder[*SyslogMessage]
+	formats    []SyslogFormat
+	facilities []int
+	severities []int
+	timestamp  bool
+	fields     []string
+	sdIDs      []string
+}
+
+// SyslogCheck returns an Expecter matching the syslog messages that
+// can be parsed and comply with the configured rules. Messages that
+// cannot be parsed are reported under the "syslog decoder" title, or
+// "syslog-strict decoder" with [SyslogConformance.Strict].
+func SyslogCheck() *SyslogConformance {
+	return &SyslogConformance{
+		dec: SyslogDecoder(false),
+	}
+}
+
+// Strict parses the messages in strict mode, see [ParseSyslog],
+// to enforce the rules of the RFCs.
+func (c *SyslogConformance) Strict() *SyslogConformance {
+	c.dec = SyslogDecoder(true)
+	return c
+}
+
+// Formats restricts the RFCs the messages may follow.
+func (c *SyslogConformance) Formats(formats ...SyslogFormat) *SyslogConformance {
+	c.formats = append(c.formats, formats...)
+	return c
+}
+
+// Facilities restricts the facilities of the messages, e.g. 16 to 23
+// for local0 to local7.
+func (c *SyslogConformance) Facilities(facilities ...int) *SyslogConformance {
+	c.facilities = append(c.facilities, facilities...)
+	return c
+}
+
+// Severities restricts the severities of the messages, 0 for
+// emergency to 7 for debug.
+func (c *SyslogConformance) Severities(severities ...int) *SyslogConformance {
+	c.severities = append(c.severities, severities...)
+	return c
+}
+
+// RequireTimestamp rejects the messages without a timestamp.
+func (c *SyslogConformance) RequireTimestamp() *SyslogConformance {
+	c.timestamp = true
+	return c
+}
+
+// RequireFields rejects the messages where any of the given header
+// fields, [SyslogHostname], [SyslogAppName], [SyslogProcID] or
+// [SyslogMsgID], is missing or set to the NILVALUE.
+func (c *SyslogConformance) RequireFields(fields ...string) *SyslogConformance {
+	c.fields = append(c.fields, fields...)
+	return c
+}
+
+// RequireSD rejects the messages without structured data elements
+// with the given IDs, e.g. "origin" or "meta".
+func (c *SyslogConformance) RequireSD(ids ...string) *SyslogConformance {
+	c.sdIDs = append(c.sdIDs, ids...)
+	return c
+}
+
+// Only for satisfy the Expecter interface.
+func (c *SyslogConformance) Expect(actual []byte) bool {
+	return false
+}
+
+func (c *SyslogConformance) Decoder() Decoding {
+	return c.dec
+}
+
+func (c *SyslogConformance) ExpectDecoded(m *SyslogMessage) bool {
+	return len(c.violations(m)) == 0
+}
+
+// ExplainDecoded returns an error describing every rule
+// the message breaks.
+func (c *SyslogConformance) ExplainDecoded(m *SyslogMessage) error {
+	return errors.Join(c.violations(m)...)
+}
+
+func (c *SyslogConformance) violations(m *SyslogMessage) []error {
+	var errs []error
+
+	if len(c.formats) > 0 && !slices.Contains(c.formats, m.Format) {
+		errs = append(errs, fmt.Errorf("unexpected format %s", m.Format))
+	}
+
+	if len(c.facilities) > 0 && !slices.Contains(c.facilities, m.Facility) {
+		errs = append(errs, fmt.Errorf("unexpected facility %d", m.Facility))
+	}
+
+	if len(c.severities) > 0 && !slices.Contains(c.severities, m.Severity) {
+		errs = append(errs, fmt.Errorf("unexpected severity %d", m.Severity))
+	}
+
+	if c.timestamp && m.Timestamp.IsZero() {
+		errs = append(errs, errors.New("missing timestamp"))
+	}
+
+	for _, f := range c.fields {
+		if m.field(f) == "" {
+			errs = append(errs, fmt.Errorf("missing %s", f))
+		}
+	}
+
+	for _, id := range c.sdIDs {
+		found := slices.ContainsFunc(m.StructuredData, func(e SDElement) bool {
+			return e.ID == id
+		})
+		if !found {
+			errs = append(errs, fmt.Errorf("missing structured data element %q", id))
+		}
+	}
+
+	return errs
+}
+
+// syslogSeverities are the names of the syslog severities, see
+// [ParseSyslogRecord].
+var syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// ParseSyslogRecord parses a syslog message with [ParseSyslog], not
+// strictly, into a [Record]. The level text is the severity name, e.g.
+// "warning", and the attributes are the [SyslogMessage.Fields] other
+// than the message.
+func ParseSyslogRecord(p []byte) (*Record, error) {
+	m, err := ParseSyslog(p, false)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := m.Fields()
+	delete(attrs, "msg")
+
+	r := &Record{
+		Time:      m.Timestamp,
+		LevelText: syslogSeverities[m.Severity],
+		Message:   m.Message,
+		Attrs:     attrs,
+		Raw:       p,
+	}
+
+	r.Level = parseLevel(r.LevelText)
+	return r, nil
+}
@@ -0,0 +1,251 @@
+package wtester
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseSyslog(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		msg    string
+		strict bool
+		err    string
+		check  func(m *SyslogMessage) bool
+	}{
+		"RFC 5424 with structured data": {
+			msg:    `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventID="1011"][examplePriority@32473 class="high"] BOMAn application event` + "\n",
+			strict: true,
+			check: func(m *SyslogMessage) bool {
+				return m.Format == SyslogRFC5424 && m.Facility == 20 && m.Severity == 5 &&
+					m.Timestamp.Equal(time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC)) &&
+					m.Hostname == "mymachine.example.com" && m.AppName == "evntslog" && m.ProcID == "" && m.MsgID == "ID47" &&
+					len(m.StructuredData) == 2 && m.StructuredData[0].Params[1] == SDParam{"eventID", "1011"} &&
+					m.Message == "BOMAn application event"
+			},
+		},
+		"RFC 5424 with nil values": {
+			msg:    "<34>1 - - - - - -",
+			strict: true,
+			check: func(m *SyslogMessage) bool {
+				return m.Timestamp.IsZero() && m.Hostname == "" && m.Message == ""
+			},
+		},
+		"Escaped SD values": {
+			msg:    `<14>1 - host app 12 - [meta q="a \"b\" \] c\\"] msg`,
+			strict: true,
+			check: func(m *SyslogMessage) bool {
+				return m.StructuredData[0].Params[0].Value == `a "b" ] c\` && m.ProcID == "12"
+			},
+		},
+		"BOM is stripped": {
+			msg:    "<14>1 - host app - - - \ufeffcafé",
+			strict: true,
+			check: func(m *SyslogMessage) bool {
+				return m.Message == "café"
+			},
+		},
+		"RFC 3164": {
+			msg: "<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8",
+			check: func(m *SyslogMessage) bool {
+				return m.Format == SyslogRFC3164 && m.Facility == 4 && m.Severity == 2 &&
+					m.Timestamp.Month() == time.October && m.Hostname == "mymachine" &&
+					m.AppName == "su" && m.ProcID == "230" && m.Message == "'su root' failed for lonvick on /dev/pts/8"
+			},
+		},
+		"RFC 3164 without header": {
+			msg: "<13>hello",
+			check: func(m *SyslogMessage) bool {
+				return m.Format == SyslogRFC3164 && m.Message == "hello"
+			},
+		},
+		"Missing PRI":          {msg: "1 - - - - - -", err: "expected <PRI>"},
+		"PRI out of range":     {msg: "<192>1 - - - - - -", err: `invalid PRI "192", expected 0 to 191`},
+		"PRI leading zero":     {msg: "<013>1 - - - - - -", err: `invalid PRI "013", expected 0 to 191`},
+		"Strict version":       {msg: "<13>2 - - - - - -", strict: true, err: "unsupported version 2"},
+		"Missing header":       {msg: "<13>1 - host", err: "expected TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA"},
+		"Lowercase time":       {msg: "<13>1 2003-10-11t22:14:15Z - - - - -", strict: true, err: `invalid timestamp "2003-10-11t22:14:15Z"`},
+		"Too precise time":     {msg: "<13>1 2003-10-11T22:14:15.0000003Z - - - - -", strict: true, err: `invalid timestamp "2003-10-11T22:14:15.0000003Z"`},
+		"Lenient time":         {msg: "<13>1 2003-10-11T22:14:15.0000003Z - - - - -"},
+		"Local time":           {msg: "<13>1 2003-10-11T22:14:15 - - - - -", err: `invalid timestamp "2003-10-11T22:14:15"`},
+		"Long app name":        {msg: "<13>1 - host aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa - - -", strict: true, err: "invalid app_name, expected 1 to 48 characters"},
+		"Non ASCII hostname":   {msg: "<13>1 - hôte app - - -", strict: true, err: `invalid hostname, character 'Ã' is not printable US-ASCII`},
+		"Lenient hostname":     {msg: "<13>1 - hôte app - - -"},
+		"Missing SD":           {msg: "<13>1 - host app - - msg", err: "expected STRUCTURED-DATA"},
+		"Unclosed SD":          {msg: `<13>1 - host app - - [meta a="1"`, err: `expected ] closing "meta"`},
+		"Empty SD-ID":          {msg: `<13>1 - host app - - [ a="1"]`, err: "empty SD-ID"},
+		"Long SD-ID":           {msg: `<13>1 - host app - - [abcdefghijklmnopqrstuvwxyz0123456]`, strict: true, err: `invalid SD-ID "abcdefghijklmnopqrstuvwxyz0123456", expected at most 32 characters`},
+		"Unquoted value":       {msg: `<13>1 - host app - - [meta a=1]`, err: `expected ="value" after "a"`},
+		"Unescaped bracket":    {msg: `<13>1 - host app - - [meta a="]"]`, strict: true, err: `invalid value of "a": unescaped ]`},
+		"Unclosed value":       {msg: `<13>1 - host app - - [meta a="1]`, err: `invalid value of "a": missing closing quote`},
+		"No space before MSG":  {msg: `<13>1 - host app - - [meta]msg`, err: "expected a space before MSG"},
+		"Strict RFC 3164":      {msg: "<13>hello", strict: true, err: "expected an RFC 3164 TIMESTAMP HOSTNAME header"},
+		"RFC 3164 without tag": {msg: "<13>Oct 11 22:14:15 host", strict: true, err: "expected a TAG"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := ParseSyslog([]byte(tt.msg), tt.strict)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if tt.check != nil && !tt.check(m) {
+				t.Fatalf("unexpected message %+v", m)
+			}
+		})
+	}
+}
+
+func TestSyslogCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		exp   *SyslogConformance
+		msg   string
+		title string
+		err   string
+	}{
+		"Conforming message": {
+			exp: SyslogCheck().Strict().Formats(SyslogRFC5424).Facilities(16, 17).Severities(3, 4, 6).
+				RequireTimestamp().RequireFields(SyslogHostname, SyslogAppName).RequireSD("origin"),
+			msg: `<134>1 2024-05-01T10:00:00Z web api - - [origin ip="10.0.0.1"] started`,
+		},
+		"Wrong format": {
+			exp: SyslogCheck().Formats(SyslogRFC5424),
+			msg: "<134>May  1 10:00:00 web api: started",
+			err: "unexpected format RFC 3164",
+		},
+		"Wrong facility and severity": {
+			exp: SyslogCheck().Facilities(16).Severities(6),
+			msg: "<15>1 - web api - - - started",
+			err: "unexpected facility 1\nunexpected severity 7",
+		},
+		"Missing timestamp": {
+			exp: SyslogCheck().RequireTimestamp(),
+			msg: "<134>1 - web api - - - started",
+			err: "missing timestamp",
+		},
+		"Missing fields": {
+			exp: SyslogCheck().RequireFields(SyslogProcID, SyslogMsgID),
+			msg: "<134>1 - web api - REQ - started",
+			err: "missing procid",
+		},
+		"Missing SD element": {
+			exp: SyslogCheck().RequireSD("origin", "meta"),
+			msg: `<134>1 - web api - - [meta seq="1"] started`,
+			err: `missing structured data element "origin"`,
+		},
+		"Not conforming in strict mode": {
+			exp:   SyslogCheck().Strict(),
+			msg:   `<134>1 - web api - - [meta seq="]"] started`,
+			title: "syslog-strict decoder",
+			err:   `invalid value of "seq": unescaped ]`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt := NewWTester(io.Discard)
+			wt.Expect(name, tt.exp).Every()
+
+			if _, err := wt.Write([]byte(tt.msg)); err != nil {
+				t.Fatalf("expected no error writing, got %v", err)
+			}
+
+			err := wt.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			ve, ok := err.(*ValidationErrors)
+			if !ok {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+
+			title := name
+			if tt.title != "" {
+				title = tt.title
+			}
+
+			i := slices.IndexFunc(ve.Errs, func(e ExpectError) bool { return e.Title == title })
+			if i < 0 {
+				t.Fatalf("expected errors for %q, got %v", title, err)
+			}
+
+			if got := ve.Errs[i].Errors[0].Err.Error(); got != tt.err {
+				t.Fatalf("expected error %q, got %q", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestParseSyslogRecord(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard)
+	wt.Expect("Syslog fields", RecordMatch(func(r *Record) bool {
+		sd, _ := r.Attrs["sd"].(map[string]any)
+		origin, _ := sd["origin"].(map[string]any)
+
+		return r.Level == slog.LevelWarn && r.LevelText == "warning" &&
+			r.Message == "disk almost full" && r.Attrs["app_name"] == "agent" &&
+			r.Attrs["facility"] == float64(16) && origin["ip"] == "10.0.0.1"
+	})).Every()
+
+	if _, err := wt.Write([]byte(`<132>1 2024-05-01T10:00:00Z web agent - - [origin ip="10.0.0.1"] disk almost full`)); err != nil {
+		t.Fatalf("expected no error writing, got %v", err)
+	}
+
+	if err := wt.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestWTester_SyslogJSON(t *testing.T) {
+	t.Parallel()
+
+	wt := NewWTester(io.Discard).SyslogJSON(false)
+	wt.Expect("password masked", MaskPolicy().FullMask("sd.auth.password")).Every()
+	wt.Expect("no token", MaskPolicy().Absent("sd.auth.token")).Every()
+
+	io.WriteString(wt, `<38>1 2024-01-02T12:30:45Z host api - login [auth user="jane" password="****"] login succeeded`+"\n")
+	io.WriteString(wt, `<38>1 2024-01-02T12:30:46Z host api - login [auth user="joe" password="hunter2"] login succeeded`+"\n")
+	io.WriteString(wt, "not syslog\n")
+
+	ve, ok := wt.Validate().(*ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors")
+	}
+
+	titles := make([]string, 0, len(ve.Errs))
+	for _, e := range ve.Errs {
+		if len(e.Errors) != 1 {
+			t.Fatalf("expected a single failure for %q, got %v", e.Title, e.Errors)
+		}
+		titles = append(titles, e.Title)
+	}
+
+	slices.Sort(titles)
+	if !slices.Equal(titles, []string{"json decoder", "password masked"}) {
+		t.Fatalf("expected the unmasked password and the decode failure, got %v", ve)
+	}
+}